@@ -0,0 +1,124 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Role is the role a client plays in the contract. It is read from the hyperpay.role attribute
+// of the client's X.509 certificate.
+type Role string
+
+const (
+	// RoleAdmin manages the ledger: it can seed, delete and migrate accounts and configure FX.
+	RoleAdmin Role = "admin"
+	// RoleTeller creates accounts on behalf of the bank.
+	RoleTeller Role = "teller"
+	// RoleAuditor can only read.
+	RoleAuditor Role = "auditor"
+	// RoleCustomer moves the funds of the accounts it owns.
+	RoleCustomer Role = "customer"
+	// RoleCompliance reads, and freezes the accounts under investigation.
+	RoleCompliance Role = "compliance"
+)
+
+// roleAttribute is the certificate attribute holding the client's role.
+const roleAttribute = "hyperpay.role"
+
+var allRoles = []Role{RoleAdmin, RoleTeller, RoleAuditor, RoleCustomer, RoleCompliance}
+
+// permissions lists the roles allowed to invoke each contract function.
+var permissions = map[string][]Role{
+	"AccountExists":           allRoles,
+	"ReadAccount":             allRoles,
+	"GetAllTxs":               allRoles,
+	"GetStatement":            allRoles,
+	"GetTransfer":             allRoles,
+	"ListTransfersForAccount": allRoles,
+	"GetFXConfig":             allRoles,
+	"GetFXRates":              allRoles,
+	"ListAccounts":            allRoles,
+	"QueryAccounts":           allRoles,
+	"BatchTransfer":           {RoleAdmin, RoleTeller, RoleCustomer},
+	"CheckBatchTransfer":      {RoleAdmin, RoleTeller, RoleCustomer},
+	"CreateAccount":           {RoleAdmin, RoleTeller},
+	"Transfer":                {RoleAdmin, RoleTeller, RoleCustomer},
+	"TransferWithConversion":  {RoleAdmin, RoleTeller, RoleCustomer},
+	"TransferOwnership":       {RoleAdmin, RoleTeller, RoleCustomer},
+	"DeleteAccount":           {RoleAdmin},
+	"InitLedger":              {RoleAdmin},
+	"MigrateAccounts":         {RoleAdmin},
+	"SetFXConfig":             {RoleAdmin},
+	"SetFXRate":               {RoleAdmin},
+	"CloseAccount":            {RoleAdmin},
+	"SetAccountStatus":        {RoleAdmin, RoleTeller},
+	"SetAccountLimits":        {RoleAdmin, RoleTeller},
+	"GetAccountLimits":        allRoles,
+	"GetFeeSchedule":          allRoles,
+	"QuoteTransfer":           allRoles,
+	"SetFeeSchedule":          {RoleAdmin},
+	"GetEscrow":               allRoles,
+	"CreateEscrow":            {RoleAdmin, RoleTeller, RoleCustomer},
+	"ClaimEscrow":             {RoleAdmin, RoleTeller, RoleCustomer},
+	"RefundEscrow":            {RoleAdmin, RoleTeller, RoleCustomer},
+	"FreezeAccount":           {RoleAdmin, RoleCompliance},
+	"UnfreezeAccount":         {RoleAdmin, RoleCompliance},
+}
+
+// getClientRole gets the client role from the hyperpay.role certificate attribute. Clients without
+// the attribute are admins when their certificate has the admin OU, and customers otherwise.
+func getClientRole(ctx contractapi.TransactionContextInterface) (Role, error) {
+	value, found, err := ctx.GetClientIdentity().GetAttributeValue(roleAttribute)
+	if err != nil {
+		return "", fmt.Errorf("failed getting client's role: %v", err)
+	}
+	if found {
+		for _, role := range allRoles {
+			if string(role) == value {
+				return role, nil
+			}
+		}
+		return "", newError(CodeUnauthorized, "unknown role %q in the %s attribute of the client's certificate", value, roleAttribute)
+	}
+
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return "", fmt.Errorf("failed getting client's certificate: %v", err)
+	}
+	if cert != nil {
+		for _, ou := range cert.Subject.OrganizationalUnit {
+			if strings.EqualFold(ou, string(RoleAdmin)) {
+				return RoleAdmin, nil
+			}
+		}
+	}
+
+	return RoleCustomer, nil
+}
+
+// verifyClientRole checks the client's role is allowed to invoke the given contract function.
+func verifyClientRole(ctx contractapi.TransactionContextInterface, function string) error {
+	role, err := getClientRole(ctx)
+	if err != nil {
+		return err
+	}
+
+	allowed := permissions[function]
+	for _, r := range allowed {
+		if r == role {
+			return nil
+		}
+	}
+
+	names := make([]string, len(allowed))
+	for i, r := range allowed {
+		names[i] = string(r)
+	}
+	return newError(CodeUnauthorized, "client with role %s is not authorized to call %s (allowed roles: %s)",
+		role,
+		function,
+		strings.Join(names, ", "),
+	)
+}
@@ -0,0 +1,323 @@
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// maxBatchSize is the maximum number of transfers of a batch, which keeps the transaction within
+// the size a peer endorses in reasonable time.
+const maxBatchSize = 1000
+
+// BatchTransferItem is a transfer of a batch. The amount is expressed in minor units of the
+// currency of both accounts.
+type BatchTransferItem struct {
+	FromID string `json:"FromID"`
+	ToID   string `json:"ToID"`
+	Amount Amount `json:"Amount"`
+	Memo   string `json:"Memo,omitempty" metadata:"Memo,optional"`
+}
+
+// BatchTransferFailure is a transfer of a batch that fails, identified by its index in the batch,
+// with the code and message of the error it fails with.
+type BatchTransferFailure struct {
+	Index   int       `json:"Index"`
+	Code    ErrorCode `json:"Code"`
+	Message string    `json:"Message"`
+}
+
+// batchPlan is a batch of transfers applied to in-memory copies of its accounts.
+type batchPlan struct {
+	accounts map[string]*Account
+	// accountIDs are the IDs of the accounts in the order the batch first involves them, so
+	// that they are written in a deterministic order.
+	accountIDs []string
+	// available is what remains of the balance each source account had before the batch, once
+	// debited the transfers planned so far. Credits of the batch are not available to its debits.
+	available map[string]Amount
+	// owned records the accounts the client is the owner of.
+	owned map[string]bool
+	// limits are the limits and usage of the source accounts, with the transfers planned so far.
+	limits map[string]*debitLimits
+	// planned are the transfers planned, in the order they were planned.
+	planned []plannedTransfer
+}
+
+// plannedTransfer is a transfer of a batch with its fee, and the balances of its source and
+// destination accounts right after it.
+type plannedTransfer struct {
+	fee          Amount
+	feeCollector string
+	fromBalance  Amount
+	toBalance    Amount
+}
+
+// BatchTransfer applies the given transfers atomically: either all of them are made, or none is
+// when any of them fails. Every transfer must be between different accounts holding the same
+// currency, made by the owner of the source account, and the total debited from each account,
+// fees included, must not exceed the balance it had before the batch nor the limits of the
+// account. Each transfer is charged the fee Transfer would charge. The records of the transfers
+// are returned in the order of the batch.
+func (s *SmartContract) BatchTransfer(ctx contractapi.TransactionContextInterface, transfers []BatchTransferItem) ([]*TransferRecord, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "BatchTransfer")
+	if err != nil {
+		return nil, err
+	}
+
+	plan, failures, err := planBatch(ctx, transfers)
+	if err != nil {
+		return nil, err
+	}
+	if len(failures) > 0 {
+		failure := failures[0]
+		return nil, newError(failure.Code, "the transfer at index %d of the batch failed: %s", failure.Index, failure.Message)
+	}
+
+	for _, accountID := range plan.accountIDs {
+		if err := putAccount(ctx, plan.accounts[accountID]); err != nil {
+			return nil, err
+		}
+		if debit, ok := plan.limits[accountID]; ok {
+			if err := debit.put(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	records := make([]*TransferRecord, len(transfers))
+	event := &BatchTransferEvent{Transfers: make([]*TransferEvent, len(transfers))}
+	// Every transfer was planned, since none failed, so the planned transfers are in the order of
+	// the batch.
+	for i, item := range transfers {
+		fromAcc, toAcc := plan.accounts[item.FromID], plan.accounts[item.ToID]
+		record, err := newTransferRecord(ctx, fromAcc, toAcc, item.Amount, item.Amount, item.Memo)
+		if err != nil {
+			return nil, err
+		}
+		// The transfers of a batch share its transaction, so their IDs carry their index, padded so
+		// that the index of the account lists them in the order of the batch.
+		record.ID = fmt.Sprintf("%s.%03d", record.TxID, i)
+		planned := plan.planned[i]
+		record.Fee = planned.fee
+		record.FeeCollector = planned.feeCollector
+		if err := putTransfer(ctx, record); err != nil {
+			return nil, err
+		}
+		records[i] = record
+		event.Transfers[i] = &TransferEvent{
+			TransferID:   record.ID,
+			FromID:       item.FromID,
+			ToID:         item.ToID,
+			Amount:       item.Amount,
+			Currency:     fromAcc.Currency,
+			ToAmount:     item.Amount,
+			ToCurrency:   toAcc.Currency,
+			FromBalance:  planned.fromBalance,
+			ToBalance:    planned.toBalance,
+			Fee:          planned.fee,
+			FeeCollector: planned.feeCollector,
+		}
+	}
+
+	err = emitEvent(ctx, EventBatchTransferred, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// CheckBatchTransfer returns the transfers of the given batch that would make BatchTransfer fail,
+// without making any of them. Unlike BatchTransfer, it checks every transfer instead of stopping at
+// the first failure; a failed transfer is left out when checking the balance of the ones after it.
+func (s *SmartContract) CheckBatchTransfer(ctx contractapi.TransactionContextInterface, transfers []BatchTransferItem) ([]*BatchTransferFailure, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "CheckBatchTransfer")
+	if err != nil {
+		return nil, err
+	}
+
+	_, failures, err := planBatch(ctx, transfers)
+	if err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+// planBatch plans the transfers of a batch, returning the failures of those that cannot be made.
+// Errors other than the failures of the transfers are returned as the error.
+func planBatch(ctx contractapi.TransactionContextInterface, transfers []BatchTransferItem) (*batchPlan, []*BatchTransferFailure, error) {
+	if len(transfers) == 0 {
+		return nil, nil, newError(CodeInvalidArgument, "the batch has no transfers")
+	}
+	if len(transfers) > maxBatchSize {
+		return nil, nil, newError(CodeInvalidArgument, "the batch has %d transfers, the maximum is %d", len(transfers), maxBatchSize)
+	}
+
+	plan := &batchPlan{
+		accounts:  make(map[string]*Account),
+		available: make(map[string]Amount),
+		owned:     make(map[string]bool),
+		limits:    make(map[string]*debitLimits),
+	}
+	failures := []*BatchTransferFailure{}
+	for i, item := range transfers {
+		err := plan.add(ctx, item)
+		if err == nil {
+			continue
+		}
+		contractErr, ok := err.(*Error)
+		if !ok {
+			return nil, nil, err
+		}
+		failures = append(failures, &BatchTransferFailure{Index: i, Code: contractErr.Code, Message: contractErr.Message})
+	}
+	return plan, failures, nil
+}
+
+// add plans a transfer of the batch, leaving the balances of the plan as they were when the
+// transfer fails.
+func (plan *batchPlan) add(ctx contractapi.TransactionContextInterface, item BatchTransferItem) error {
+	if item.Amount <= 0 {
+		return newError(CodeInvalidArgument, "amount must be positive")
+	}
+	if item.FromID == item.ToID {
+		return newError(CodeInvalidArgument, "the source and destination accounts must differ")
+	}
+	err := verifyMemo(item.Memo)
+	if err != nil {
+		return err
+	}
+
+	fromAcc, err := plan.account(ctx, item.FromID)
+	if err != nil {
+		return err
+	}
+	if !plan.owned[fromAcc.ID] {
+		err = verifyClientIsAccountOwner(ctx, fromAcc)
+		if err != nil {
+			return err
+		}
+		plan.owned[fromAcc.ID] = true
+	}
+	err = verifyCanDebit(fromAcc)
+	if err != nil {
+		return err
+	}
+
+	toAcc, err := plan.account(ctx, item.ToID)
+	if err != nil {
+		return err
+	}
+	err = verifyCanCredit(toAcc)
+	if err != nil {
+		return err
+	}
+
+	if fromAcc.Currency != toAcc.Currency {
+		return newError(CodeFailedPrecondition, "cannot transfer between accounts in different currencies (%s and %s)", fromAcc.Currency, toAcc.Currency)
+	}
+
+	fee, collectorID, err := getTransferFee(ctx, fromAcc, item.Amount)
+	if err != nil {
+		return err
+	}
+	total, err := addAmounts(item.Amount, fee)
+	if err != nil {
+		return newError(CodeInvalidArgument, "the amount plus its fee is out of range")
+	}
+	if plan.available[fromAcc.ID] < total {
+		if fee > 0 {
+			return newError(CodeInsufficientFunds, "the source account does not have enough balance for the transfers of the batch and the fee of %s", fee.Format(fromAcc.Currency))
+		}
+		return newError(CodeInsufficientFunds, "the source account does not have enough balance for the transfers of the batch")
+	}
+
+	toBalance, err := addAmounts(toAcc.Balance, item.Amount)
+	if err != nil {
+		return newError(CodeFailedPrecondition, "the destination account cannot hold the amount: %v", err)
+	}
+
+	// The fee collector may be the destination account, which is then credited with both.
+	var collector *Account
+	collectorBalance := toBalance
+	if fee > 0 {
+		collector, err = plan.account(ctx, collectorID)
+		if err != nil {
+			return err
+		}
+		if collector != toAcc {
+			err = verifyCanCredit(collector)
+			if err != nil {
+				return err
+			}
+			collectorBalance = collector.Balance
+		}
+		collectorBalance, err = addAmounts(collectorBalance, fee)
+		if err != nil {
+			return newError(CodeFailedPrecondition, "the fee collector account cannot hold the fee: %v", err)
+		}
+	}
+
+	debit, ok := plan.limits[fromAcc.ID]
+	if !ok {
+		debit, err = getDebitLimits(ctx, fromAcc)
+		if err != nil {
+			return err
+		}
+		plan.limits[fromAcc.ID] = debit
+	}
+	err = debit.add(item.Amount)
+	if err != nil {
+		return err
+	}
+
+	plan.available[fromAcc.ID] -= total
+	fromAcc.Balance -= total
+	toAcc.Balance = toBalance
+	if collector != nil {
+		collector.Balance = collectorBalance
+	}
+	plan.planned = append(plan.planned, plannedTransfer{
+		fee:          fee,
+		feeCollector: collectorID,
+		fromBalance:  fromAcc.Balance,
+		toBalance:    toAcc.Balance,
+	})
+	return nil
+}
+
+// account returns the in-memory copy of the given account, reading it on first use.
+func (plan *batchPlan) account(ctx contractapi.TransactionContextInterface, accountID string) (*Account, error) {
+	if account, ok := plan.accounts[accountID]; ok {
+		return account, nil
+	}
+	account, err := getExistingAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	plan.accounts[accountID] = account
+	plan.accountIDs = append(plan.accountIDs, accountID)
+	plan.available[accountID] = account.Balance
+	return account, nil
+}
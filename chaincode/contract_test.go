@@ -0,0 +1,963 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode/memledger"
+)
+
+const (
+	testChannel = "mychannel"
+	testOrg     = "Org1MSP"
+	// testSecret is the hex encoded secret of the escrow of the fixture.
+	testSecret = "736563726574"
+)
+
+// fixture is a ledger with a client of every role, plus a client of another org. The admin owns
+// the accounts usd1, usd2, eur1 and empty, and the customer owns cust; there is a USD to EUR rate,
+// a transfer of 5.00 from usd1 to usd2 and an escrow of 10.00 from usd1 to usd2 locked by testSecret
+// for an hour. usd1 is left with 85.00.
+type fixture struct {
+	t      *testing.T
+	ledger *memledger.Ledger
+	cc     *contractapi.ContractChaincode
+	// ids are the clients by role; outsider is an admin of another org.
+	ids map[string]*memledger.Identity
+	now time.Time
+
+	customerID string
+	transferID string
+	escrowID   string
+}
+
+// testChaincode and testIdentities are shared by the fixtures, since building the chaincode and
+// the certificates is the slow part of a fixture.
+var (
+	testChaincode  *contractapi.ContractChaincode
+	testIdentities map[string]*memledger.Identity
+)
+
+// newFixture returns a new fixture. Every transaction on its ledger is a second after the previous.
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	if testChaincode == nil {
+		cc, err := contractapi.NewChaincode(new(SmartContract))
+		if err != nil {
+			t.Fatalf("failed to create the chaincode: %v", err)
+		}
+		identities := make(map[string]*memledger.Identity)
+		addIdentity := func(name, mspID string, organizationalUnits []string, attributes map[string]string) {
+			identity, err := memledger.NewIdentity(mspID, name, organizationalUnits, attributes)
+			if err != nil {
+				t.Fatalf("failed to create the %s identity: %v", name, err)
+			}
+			identities[name] = identity
+		}
+		addIdentity("admin", testOrg, []string{"admin"}, nil)
+		addIdentity("customer", testOrg, []string{"client"}, nil)
+		for _, role := range []Role{RoleTeller, RoleAuditor, RoleCompliance} {
+			addIdentity(string(role), testOrg, []string{"client"}, map[string]string{roleAttribute: string(role)})
+		}
+		addIdentity("outsider", "Org2MSP", []string{"admin"}, nil)
+		testChaincode, testIdentities = cc, identities
+	}
+
+	f := &fixture{
+		t:      t,
+		ledger: memledger.NewLedger(testChannel, testOrg),
+		cc:     testChaincode,
+		ids:    testIdentities,
+		now:    time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC),
+	}
+	f.ledger.SetClock(func() time.Time {
+		f.now = f.now.Add(time.Second)
+		return f.now
+	})
+
+	f.customerID = string(f.mustSubmit("customer", "GetClientID"))
+	f.mustSubmit("admin", "InitLedger", jsonArg(t, []SeedAccount{
+		{ID: "usd1", Balance: 10000, Currency: "USD", Bank: "BankA"},
+		{ID: "usd2", Balance: 20000, Currency: "USD", Bank: "BankB"},
+		{ID: "eur1", Balance: 30000, Currency: "EUR", Bank: "BankA"},
+		{ID: "empty", Balance: 0, Currency: "USD", Bank: "BankA"},
+		{ID: "cust", Balance: 5000, Currency: "USD", Bank: "BankB"},
+	}), "false")
+	f.mustSubmit("admin", "TransferOwnership", "cust", f.customerID)
+	f.mustSubmit("admin", "SetFXRate", "USD", "EUR", "0.9")
+
+	var transfer TransferRecord
+	f.mustDecode(f.mustSubmit("admin", "Transfer", "usd1", "usd2", "500", "setup"), &transfer)
+	f.transferID = transfer.ID
+
+	var escrow Escrow
+	f.mustDecode(f.mustSubmit("admin", "CreateEscrow", "usd1", "usd2", "1000", testHashlock(), f.now.Add(time.Hour).Format(time.RFC3339)), &escrow)
+	f.escrowID = escrow.ID
+
+	return f
+}
+
+// submit submits a transaction on behalf of the given client, returning its payload or the error
+// message of the contract.
+func (f *fixture) submit(caller string, args ...string) ([]byte, error) {
+	f.t.Helper()
+	identity, ok := f.ids[caller]
+	if !ok {
+		f.t.Fatalf("unknown client %s", caller)
+	}
+	_, response := f.ledger.Submit(f.cc, identity, args...)
+	if response.Status >= shim.ERRORTHRESHOLD {
+		return nil, errors.New(response.Message)
+	}
+	return response.Payload, nil
+}
+
+// mustSubmit submits a transaction that must succeed.
+func (f *fixture) mustSubmit(caller string, args ...string) []byte {
+	f.t.Helper()
+	payload, err := f.submit(caller, args...)
+	if err != nil {
+		f.t.Fatalf("%s failed: %v", args[0], err)
+	}
+	return payload
+}
+
+// mustDecode decodes the JSON payload of a transaction into v.
+func (f *fixture) mustDecode(payload []byte, v interface{}) {
+	f.t.Helper()
+	if err := json.Unmarshal(payload, v); err != nil {
+		f.t.Fatalf("failed to decode %s: %v", payload, err)
+	}
+}
+
+// account reads the given account from the ledger.
+func (f *fixture) account(accountID string) *Account {
+	f.t.Helper()
+	var account Account
+	f.mustDecode(f.mustSubmit("admin", "ReadAccount", accountID), &account)
+	return &account
+}
+
+// expectBalance checks the balance of the given account.
+func (f *fixture) expectBalance(accountID string, balance Amount) {
+	f.t.Helper()
+	if got := f.account(accountID).Balance; got != balance {
+		f.t.Errorf("the balance of %s is %d, want %d", accountID, got, balance)
+	}
+}
+
+// jsonArg encodes an argument of a transaction as JSON.
+func jsonArg(t *testing.T, v interface{}) string {
+	t.Helper()
+	arg, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to encode %v: %v", v, err)
+	}
+	return string(arg)
+}
+
+// testHashlock returns the hashlock of testSecret.
+func testHashlock() string {
+	secret, _ := hex.DecodeString(testSecret)
+	hash := sha256.Sum256(secret)
+	return hex.EncodeToString(hash[:])
+}
+
+// errorCode returns the code of an error returned by submit, empty for errors without a code.
+func errorCode(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	contractErr, ok := ParseError(err.Error())
+	if !ok {
+		return ""
+	}
+	return contractErr.Code
+}
+
+// contractCase is a transaction run on a new fixture.
+type contractCase struct {
+	name   string
+	caller string
+	// args returns the function and arguments of the transaction.
+	args func(f *fixture) []string
+	// setup, when set, prepares the fixture before the transaction.
+	setup func(f *fixture)
+	// code is the code of the error the transaction fails with, empty when it succeeds.
+	code ErrorCode
+	// check, when set, checks the payload and the ledger after a successful transaction.
+	check func(f *fixture, payload []byte)
+}
+
+// args returns the args function of a transaction with fixed arguments.
+func args(function string, arguments ...string) func(f *fixture) []string {
+	return func(f *fixture) []string {
+		return append([]string{function}, arguments...)
+	}
+}
+
+// run runs the cases, each on a new fixture.
+func run(t *testing.T, cases []contractCase) {
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			f := newFixture(t)
+			if c.setup != nil {
+				c.setup(f)
+			}
+			payload, err := f.submit(c.caller, c.args(f)...)
+			if c.code == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if c.check != nil {
+					c.check(f, payload)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("succeeded, want a %s error", c.code)
+			}
+			if got := errorCode(err); got != c.code {
+				t.Fatalf("failed with code %q, want %s: %v", got, c.code, err)
+			}
+		})
+	}
+}
+
+// successCases run every contract function with a client allowed to call it.
+var successCases = []contractCase{
+	{
+		name: "InitLedger", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"InitLedger", jsonArg(f.t, []SeedAccount{{ID: "new", Balance: 100, Currency: "JPY", Bank: "BankC"}}), "false"}
+		},
+		check: func(f *fixture, payload []byte) {
+			f.expectBalance("new", 100)
+		},
+	},
+	{
+		name: "AccountExists", caller: "auditor", args: args("AccountExists", "usd1"),
+		check: func(f *fixture, payload []byte) {
+			if string(payload) != "true" {
+				f.t.Errorf("got %s, want true", payload)
+			}
+		},
+	},
+	{
+		name: "ReadAccount", caller: "auditor", args: args("ReadAccount", "usd1"),
+		check: func(f *fixture, payload []byte) {
+			var account Account
+			f.mustDecode(payload, &account)
+			if account.Balance != 8500 || account.Currency != "USD" || account.Status != StatusActive {
+				f.t.Errorf("got %+v", account)
+			}
+		},
+	},
+	{
+		name: "CreateAccount", caller: "teller", args: args("CreateAccount", "new", "1000", "BankC", "USD", AccountSavings),
+		check: func(f *fixture, payload []byte) {
+			if account := f.account("new"); account.Balance != 1000 || account.Type != AccountSavings {
+				f.t.Errorf("got %+v", account)
+			}
+		},
+	},
+	{
+		name: "DeleteAccount", caller: "admin", args: args("DeleteAccount", "empty"),
+		check: func(f *fixture, payload []byte) {
+			if status := f.account("empty").Status; status != StatusClosed {
+				f.t.Errorf("the account is %s, want %s", status, StatusClosed)
+			}
+		},
+	},
+	{
+		name: "TransferOwnership", caller: "admin",
+		args: func(f *fixture) []string { return []string{"TransferOwnership", "usd2", f.customerID} },
+		check: func(f *fixture, payload []byte) {
+			if owner := f.account("usd2").Owner; owner != f.customerID {
+				f.t.Errorf("the owner is %s, want %s", owner, f.customerID)
+			}
+		},
+	},
+	{
+		name: "GetClientID", caller: "customer", args: args("GetClientID"),
+		check: func(f *fixture, payload []byte) {
+			if string(payload) != f.customerID {
+				f.t.Errorf("got %s, want %s", payload, f.customerID)
+			}
+		},
+	},
+	{
+		name: "Transfer", caller: "customer", args: args("Transfer", "cust", "usd1", "1500", "rent"),
+		check: func(f *fixture, payload []byte) {
+			var transfer TransferRecord
+			f.mustDecode(payload, &transfer)
+			if transfer.Amount != 1500 || transfer.Memo != "rent" {
+				f.t.Errorf("got %+v", transfer)
+			}
+			f.expectBalance("cust", 3500)
+			f.expectBalance("usd1", 10000)
+		},
+	},
+	{
+		name: "TransferWithConversion", caller: "admin", args: args("TransferWithConversion", "usd1", "eur1", "1000", ""),
+		check: func(f *fixture, payload []byte) {
+			f.expectBalance("usd1", 7500)
+			f.expectBalance("eur1", 30900)
+		},
+	},
+	{
+		name: "BatchTransfer", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"BatchTransfer", jsonArg(f.t, []BatchTransferItem{
+				{FromID: "usd1", ToID: "usd2", Amount: 100},
+				{FromID: "usd2", ToID: "empty", Amount: 50},
+			})}
+		},
+		check: func(f *fixture, payload []byte) {
+			var records []*TransferRecord
+			f.mustDecode(payload, &records)
+			if len(records) != 2 {
+				f.t.Fatalf("got %d records, want 2", len(records))
+			}
+			f.expectBalance("usd1", 8400)
+			f.expectBalance("usd2", 20550)
+			f.expectBalance("empty", 50)
+
+			events := f.ledger.Events()
+			var event BatchTransferEvent
+			f.mustDecode(events[len(events)-1].Payload, &event)
+			balances := [][2]Amount{{8400, 20600}, {20550, 50}}
+			for i, transfer := range event.Transfers {
+				if got := [2]Amount{transfer.FromBalance, transfer.ToBalance}; got != balances[i] {
+					f.t.Errorf("the balances of the transfer %d are %v, want %v", i, got, balances[i])
+				}
+			}
+		},
+	},
+	{
+		name: "CheckBatchTransfer", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"CheckBatchTransfer", jsonArg(f.t, []BatchTransferItem{{FromID: "usd1", ToID: "usd2", Amount: 100}})}
+		},
+		check: func(f *fixture, payload []byte) {
+			var failures []*BatchTransferFailure
+			f.mustDecode(payload, &failures)
+			if len(failures) != 0 {
+				f.t.Errorf("got failures %+v", failures)
+			}
+			f.expectBalance("usd1", 8500)
+		},
+	},
+	{
+		name: "GetAllTxs", caller: "auditor", args: args("GetAllTxs", "usd1"),
+		check: func(f *fixture, payload []byte) {
+			var records []TxRecord
+			f.mustDecode(payload, &records)
+			if len(records) != 3 {
+				f.t.Errorf("got %d records, want 3", len(records))
+			}
+		},
+	},
+	{
+		name: "GetStatement", caller: "auditor", args: args("GetStatement", "usd1", "", ""),
+		check: func(f *fixture, payload []byte) {
+			var statement Statement
+			f.mustDecode(payload, &statement)
+			if statement.ClosingBalance != 8500 || len(statement.Entries) != 3 {
+				f.t.Fatalf("got %+v", statement)
+			}
+			if kind := statement.Entries[2].Kind; kind != EntryEscrow {
+				f.t.Errorf("the last entry is a %s, want %s", kind, EntryEscrow)
+			}
+		},
+	},
+	{
+		name: "GetTransfer", caller: "auditor",
+		args: func(f *fixture) []string { return []string{"GetTransfer", f.transferID} },
+		check: func(f *fixture, payload []byte) {
+			var transfer TransferRecord
+			f.mustDecode(payload, &transfer)
+			if transfer.FromID != "usd1" || transfer.ToID != "usd2" || transfer.Amount != 500 {
+				f.t.Errorf("got %+v", transfer)
+			}
+		},
+	},
+	{
+		name: "ListTransfersForAccount", caller: "auditor", args: args("ListTransfersForAccount", "usd2", "10", ""),
+		check: func(f *fixture, payload []byte) {
+			var page TransferPage
+			f.mustDecode(payload, &page)
+			if len(page.Transfers) != 1 || page.Bookmark != "" {
+				f.t.Errorf("got %+v", page)
+			}
+		},
+	},
+	{
+		name: "ListAccounts", caller: "auditor", args: args("ListAccounts", "2", ""),
+		check: func(f *fixture, payload []byte) {
+			var page AccountPage
+			f.mustDecode(payload, &page)
+			if len(page.Accounts) != 2 || page.Bookmark == "" {
+				f.t.Errorf("got %+v", page)
+			}
+		},
+	},
+	{
+		name: "QueryAccounts", caller: "auditor", args: args("QueryAccounts", "BankA", "", "USD", "1", "0", "10", ""),
+		check: func(f *fixture, payload []byte) {
+			var page AccountPage
+			f.mustDecode(payload, &page)
+			if len(page.Accounts) != 1 || page.Accounts[0].ID != "usd1" {
+				f.t.Errorf("got %+v", page)
+			}
+		},
+	},
+	{
+		name: "MigrateAccounts", caller: "admin", args: args("MigrateAccounts"),
+		check: func(f *fixture, payload []byte) {
+			if string(payload) != "0" {
+				f.t.Errorf("migrated %s accounts, want 0", payload)
+			}
+		},
+	},
+	{
+		name: "GetFXConfig", caller: "auditor", args: args("GetFXConfig"),
+		check: func(f *fixture, payload []byte) {
+			var config FXConfig
+			f.mustDecode(payload, &config)
+			if !reflect.DeepEqual(config.RateSetters, []string{testOrg}) {
+				f.t.Errorf("got %+v", config)
+			}
+		},
+	},
+	{
+		name: "SetFXConfig", caller: "admin", args: args("SetFXConfig", `["Org1MSP","Org2MSP"]`, "60"),
+		check: func(f *fixture, payload []byte) {
+			var config FXConfig
+			f.mustDecode(f.mustSubmit("admin", "GetFXConfig"), &config)
+			if len(config.RateSetters) != 2 || config.MaxRateAge != 60 {
+				f.t.Errorf("got %+v", config)
+			}
+		},
+	},
+	{
+		name: "SetFXRate", caller: "admin", args: args("SetFXRate", "EUR", "USD", "1.1"),
+		check: func(f *fixture, payload []byte) {
+			var rates []*FXRate
+			f.mustDecode(f.mustSubmit("admin", "GetFXRates"), &rates)
+			if len(rates) != 2 {
+				f.t.Errorf("got %d rates, want 2", len(rates))
+			}
+		},
+	},
+	{
+		name: "GetFXRates", caller: "auditor", args: args("GetFXRates"),
+		check: func(f *fixture, payload []byte) {
+			var rates []*FXRate
+			f.mustDecode(payload, &rates)
+			if len(rates) != 1 || rates[0].Rate != "0.9" {
+				f.t.Errorf("got %+v", rates)
+			}
+		},
+	},
+	{
+		name: "SetAccountStatus", caller: "teller", args: args("SetAccountStatus", "usd1", StatusDormant),
+		check: func(f *fixture, payload []byte) {
+			if status := f.account("usd1").Status; status != StatusDormant {
+				f.t.Errorf("the account is %s, want %s", status, StatusDormant)
+			}
+		},
+	},
+	{
+		name: "CloseAccount", caller: "admin", args: args("CloseAccount", "eur1", "eur2"),
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "CreateAccount", "eur2", "0", "BankA", "EUR", AccountChecking)
+		},
+		check: func(f *fixture, payload []byte) {
+			if status := f.account("eur1").Status; status != StatusClosed {
+				f.t.Errorf("the account is %s, want %s", status, StatusClosed)
+			}
+			f.expectBalance("eur1", 0)
+			f.expectBalance("eur2", 30000)
+		},
+	},
+	{
+		name: "SetAccountLimits", caller: "teller", args: args("SetAccountLimits", "usd1", "1000", "2000", "0"),
+		check: func(f *fixture, payload []byte) {
+			var limits AccountLimits
+			f.mustDecode(f.mustSubmit("admin", "GetAccountLimits", "usd1"), &limits)
+			if limits.MaxTransfer != 1000 || limits.Daily != 2000 || limits.Monthly != 0 {
+				f.t.Errorf("got %+v", limits)
+			}
+		},
+	},
+	{
+		name: "GetAccountLimits", caller: "auditor", args: args("GetAccountLimits", "usd1"),
+		check: func(f *fixture, payload []byte) {
+			var limits AccountLimits
+			f.mustDecode(payload, &limits)
+			if limits.AccountID != "usd1" || limits.MaxTransfer != 0 {
+				f.t.Errorf("got %+v", limits)
+			}
+		},
+	},
+	{
+		name: "SetFeeSchedule", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"SetFeeSchedule", jsonArg(f.t, []FeeRule{{Currency: "USD", Collector: "empty", Flat: 100}})}
+		},
+		check: func(f *fixture, payload []byte) {
+			f.mustSubmit("admin", "Transfer", "usd1", "usd2", "1000", "")
+			f.expectBalance("usd1", 7400)
+			f.expectBalance("empty", 100)
+		},
+	},
+	{
+		name: "GetFeeSchedule", caller: "auditor", args: args("GetFeeSchedule"),
+		check: func(f *fixture, payload []byte) {
+			var schedule FeeSchedule
+			f.mustDecode(payload, &schedule)
+			if schedule.Rules == nil || len(schedule.Rules) != 0 {
+				f.t.Errorf("got %+v", schedule)
+			}
+		},
+	},
+	{
+		name: "QuoteTransfer", caller: "auditor", args: args("QuoteTransfer", "usd1", "usd2", "10000"),
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "SetFeeSchedule", jsonArg(f.t, []FeeRule{{Currency: "USD", Collector: "empty", Percent: "1", Min: 50}}))
+		},
+		check: func(f *fixture, payload []byte) {
+			var quote FeeQuote
+			f.mustDecode(payload, &quote)
+			if quote.Fee != 100 || quote.Total != 10100 || quote.FeeCollector != "empty" {
+				f.t.Errorf("got %+v", quote)
+			}
+		},
+	},
+	{
+		name: "CreateEscrow", caller: "customer",
+		args: func(f *fixture) []string {
+			return []string{"CreateEscrow", "cust", "usd1", "2000", testHashlock(), f.now.Add(time.Hour).Format(time.RFC3339)}
+		},
+		check: func(f *fixture, payload []byte) {
+			var escrow Escrow
+			f.mustDecode(payload, &escrow)
+			if escrow.Status != EscrowLocked || escrow.Amount != 2000 {
+				f.t.Errorf("got %+v", escrow)
+			}
+			f.expectBalance("cust", 3000)
+		},
+	},
+	{
+		name: "ClaimEscrow", caller: "customer",
+		args: func(f *fixture) []string { return []string{"ClaimEscrow", f.escrowID, testSecret} },
+		check: func(f *fixture, payload []byte) {
+			var escrow Escrow
+			f.mustDecode(payload, &escrow)
+			if escrow.Status != EscrowClaimed || escrow.Preimage != testSecret {
+				f.t.Errorf("got %+v", escrow)
+			}
+			f.expectBalance("usd2", 21500)
+		},
+	},
+	{
+		name: "RefundEscrow", caller: "customer",
+		args:  func(f *fixture) []string { return []string{"RefundEscrow", f.escrowID} },
+		setup: func(f *fixture) { f.now = f.now.Add(time.Hour) },
+		check: func(f *fixture, payload []byte) {
+			var escrow Escrow
+			f.mustDecode(payload, &escrow)
+			if escrow.Status != EscrowRefunded {
+				f.t.Errorf("the escrow is %s, want %s", escrow.Status, EscrowRefunded)
+			}
+			f.expectBalance("usd1", 9500)
+		},
+	},
+	{
+		name: "GetEscrow", caller: "auditor",
+		args: func(f *fixture) []string { return []string{"GetEscrow", f.escrowID} },
+		check: func(f *fixture, payload []byte) {
+			var escrow Escrow
+			f.mustDecode(payload, &escrow)
+			if escrow.FromID != "usd1" || escrow.ToID != "usd2" || escrow.Hashlock != testHashlock() {
+				f.t.Errorf("got %+v", escrow)
+			}
+		},
+	},
+	{
+		name: "FreezeAccount", caller: "compliance", args: args("FreezeAccount", "usd1", FreezeDebit, "AML_REVIEW"),
+		check: func(f *fixture, payload []byte) {
+			freeze := f.account("usd1").Freeze
+			if freeze == nil || freeze.Mode != FreezeDebit || freeze.Reason != "AML_REVIEW" {
+				f.t.Errorf("got %+v", freeze)
+			}
+		},
+	},
+	{
+		name: "UnfreezeAccount", caller: "compliance", args: args("UnfreezeAccount", "usd1"),
+		setup: func(f *fixture) {
+			f.mustSubmit("compliance", "FreezeAccount", "usd1", FreezeFull, "AML_REVIEW")
+		},
+		check: func(f *fixture, payload []byte) {
+			if freeze := f.account("usd1").Freeze; freeze != nil {
+				f.t.Errorf("the account is still frozen: %+v", freeze)
+			}
+		},
+	},
+}
+
+func TestContractFunctions(t *testing.T) {
+	run(t, successCases)
+}
+
+// TestEveryFunctionIsCovered checks that the table of TestContractFunctions runs every function of
+// the contract.
+func TestEveryFunctionIsCovered(t *testing.T) {
+	covered := make(map[string]bool)
+	for _, c := range successCases {
+		covered[c.args(&fixture{t: t})[0]] = true
+	}
+
+	contractType := reflect.TypeOf(new(SmartContract))
+	baseType := reflect.TypeOf(new(contractapi.Contract))
+	for i := 0; i < contractType.NumMethod(); i++ {
+		name := contractType.Method(i).Name
+		if _, ok := baseType.MethodByName(name); ok {
+			continue
+		}
+		if !covered[name] {
+			t.Errorf("the contract function %s has no test case", name)
+		}
+	}
+}
+
+// TestAuthorization runs every function with each role that is not allowed to call it, and with a
+// client of an org other than the peer's.
+func TestAuthorization(t *testing.T) {
+	var cases []contractCase
+	for _, c := range successCases {
+		c := c
+		function := c.args(&fixture{t: t})[0]
+		allowed, ok := permissions[function]
+		if !ok {
+			continue
+		}
+		for _, role := range allRoles {
+			if containsRole(allowed, role) {
+				continue
+			}
+			caller := string(role)
+			cases = append(cases, contractCase{
+				name:   c.name + " as " + caller,
+				caller: caller,
+				args:   c.args,
+				setup:  c.setup,
+				code:   CodeUnauthorized,
+			})
+		}
+		cases = append(cases, contractCase{
+			name:   c.name + " from another org",
+			caller: "outsider",
+			args:   c.args,
+			setup:  c.setup,
+			code:   CodeUnauthorized,
+		})
+	}
+	run(t, cases)
+}
+
+// containsRole reports whether the role is in the list.
+func containsRole(roles []Role, role Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// errorCases run the failure paths of the contract functions, and the edge cases next to them.
+var errorCases = []contractCase{
+	{name: "Transfer with insufficient balance", caller: "admin", args: args("Transfer", "usd1", "usd2", "8501", ""), code: CodeInsufficientFunds},
+	{name: "Transfer of a non-positive amount", caller: "admin", args: args("Transfer", "usd1", "usd2", "0", ""), code: CodeInvalidArgument},
+	{name: "Transfer to the source account", caller: "admin", args: args("Transfer", "usd2", "usd2", "1000", ""), code: CodeInvalidArgument},
+	{name: "QuoteTransfer to the source account", caller: "auditor", args: args("QuoteTransfer", "usd2", "usd2", "1000"), code: CodeInvalidArgument},
+	{name: "Transfer from a missing account", caller: "admin", args: args("Transfer", "nope", "usd2", "100", ""), code: CodeNotFound},
+	{name: "Transfer between currencies", caller: "admin", args: args("Transfer", "usd1", "eur1", "100", ""), code: CodeFailedPrecondition},
+	{name: "Transfer from an account of another owner", caller: "customer", args: args("Transfer", "usd1", "cust", "100", ""), code: CodeUnauthorized},
+	{
+		name: "Transfer from a frozen account", caller: "admin", args: args("Transfer", "usd1", "usd2", "100", ""),
+		setup: func(f *fixture) { f.mustSubmit("compliance", "FreezeAccount", "usd1", FreezeDebit, "AML_REVIEW") },
+		code:  CodeAccountFrozen,
+	},
+	{
+		name: "Transfer above the daily limit", caller: "admin", args: args("Transfer", "usd1", "usd2", "600", ""),
+		setup: func(f *fixture) { f.mustSubmit("admin", "SetAccountLimits", "usd1", "0", "1000", "0") },
+		code:  CodeLimitExceeded,
+	},
+	{
+		name: "Transfer from a dormant account", caller: "admin", args: args("Transfer", "usd1", "usd2", "100", ""),
+		setup: func(f *fixture) { f.mustSubmit("admin", "SetAccountStatus", "usd1", StatusDormant) },
+		code:  CodeFailedPrecondition,
+	},
+	{name: "TransferWithConversion with insufficient balance", caller: "admin", args: args("TransferWithConversion", "usd1", "eur1", "8501", ""), code: CodeInsufficientFunds},
+	{name: "TransferWithConversion without a rate", caller: "admin", args: args("TransferWithConversion", "eur1", "usd1", "100", ""), code: CodeNotFound},
+	{
+		name: "TransferWithConversion charging a fee", caller: "admin", args: args("TransferWithConversion", "usd1", "eur1", "1000", ""),
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "SetFeeSchedule", jsonArg(f.t, []FeeRule{{Currency: "USD", Collector: "empty", Flat: 100}}))
+		},
+		check: func(f *fixture, payload []byte) {
+			var conversion Conversion
+			f.mustDecode(payload, &conversion)
+			if conversion.Fee != 100 || conversion.FeeCollector != "empty" || conversion.ToAmount != 900 {
+				f.t.Errorf("got %+v", conversion)
+			}
+			f.expectBalance("usd1", 7400)
+			f.expectBalance("empty", 100)
+			f.expectBalance("eur1", 30900)
+		},
+	},
+	{
+		name: "TransferWithConversion with insufficient balance for the fee", caller: "admin", args: args("TransferWithConversion", "usd1", "eur1", "8450", ""),
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "SetFeeSchedule", jsonArg(f.t, []FeeRule{{Currency: "USD", Collector: "empty", Flat: 100}}))
+		},
+		code: CodeInsufficientFunds,
+	},
+	{
+		name: "BatchTransfer with insufficient balance", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"BatchTransfer", jsonArg(f.t, []BatchTransferItem{
+				{FromID: "usd1", ToID: "usd2", Amount: 8000},
+				{FromID: "usd1", ToID: "empty", Amount: 501},
+			})}
+		},
+		code: CodeInsufficientFunds,
+	},
+	{name: "BatchTransfer without transfers", caller: "admin", args: args("BatchTransfer", "[]"), code: CodeInvalidArgument},
+	{
+		name: "BatchTransfer of a single transfer charging the fee of Transfer", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"BatchTransfer", jsonArg(f.t, []BatchTransferItem{{FromID: "usd1", ToID: "usd2", Amount: 1000}})}
+		},
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "SetFeeSchedule", jsonArg(f.t, []FeeRule{{Currency: "USD", Collector: "empty", Flat: 100}}))
+		},
+		check: func(f *fixture, payload []byte) {
+			var records []*TransferRecord
+			f.mustDecode(payload, &records)
+			if len(records) != 1 || records[0].Fee != 100 || records[0].FeeCollector != "empty" {
+				f.t.Fatalf("got %+v", records)
+			}
+			f.expectBalance("usd1", 7400)
+			f.expectBalance("usd2", 21500)
+			f.expectBalance("empty", 100)
+		},
+	},
+	{
+		name: "BatchTransfer charging fees to the collector", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"BatchTransfer", jsonArg(f.t, []BatchTransferItem{
+				{FromID: "usd1", ToID: "usd2", Amount: 1000},
+				{FromID: "usd2", ToID: "empty", Amount: 500},
+			})}
+		},
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "SetFeeSchedule", jsonArg(f.t, []FeeRule{{Currency: "USD", Collector: "empty", Flat: 100}}))
+		},
+		check: func(f *fixture, payload []byte) {
+			f.expectBalance("usd1", 7400)
+			f.expectBalance("usd2", 20900)
+			f.expectBalance("empty", 700)
+
+			events := f.ledger.Events()
+			var event BatchTransferEvent
+			f.mustDecode(events[len(events)-1].Payload, &event)
+			balances := [][2]Amount{{7400, 21500}, {20900, 700}}
+			for i, transfer := range event.Transfers {
+				if transfer.Fee != 100 || transfer.FeeCollector != "empty" {
+					f.t.Errorf("transfer %d: got fee %d to %q", i, transfer.Fee, transfer.FeeCollector)
+				}
+				if got := [2]Amount{transfer.FromBalance, transfer.ToBalance}; got != balances[i] {
+					f.t.Errorf("transfer %d: got balances %v, want %v", i, got, balances[i])
+				}
+			}
+		},
+	},
+	{
+		name: "BatchTransfer with insufficient balance for the fees", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"BatchTransfer", jsonArg(f.t, []BatchTransferItem{
+				{FromID: "usd1", ToID: "usd2", Amount: 4200},
+				{FromID: "usd1", ToID: "usd2", Amount: 4200},
+			})}
+		},
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "SetFeeSchedule", jsonArg(f.t, []FeeRule{{Currency: "USD", Collector: "empty", Flat: 100}}))
+		},
+		code: CodeInsufficientFunds,
+	},
+	{
+		name: "CheckBatchTransfer with insufficient balance", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"CheckBatchTransfer", jsonArg(f.t, []BatchTransferItem{
+				{FromID: "usd1", ToID: "usd2", Amount: 8000},
+				{FromID: "usd1", ToID: "empty", Amount: 501},
+			})}
+		},
+		check: func(f *fixture, payload []byte) {
+			var failures []*BatchTransferFailure
+			f.mustDecode(payload, &failures)
+			if len(failures) != 1 || failures[0].Index != 1 || failures[0].Code != CodeInsufficientFunds {
+				f.t.Errorf("got failures %+v", failures)
+			}
+		},
+	},
+	{
+		name: "CreateEscrow with insufficient balance", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"CreateEscrow", "usd1", "usd2", "8501", testHashlock(), f.now.Add(time.Hour).Format(time.RFC3339)}
+		},
+		code: CodeInsufficientFunds,
+	},
+	{
+		name: "CreateEscrow expiring in the past", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"CreateEscrow", "usd1", "usd2", "100", testHashlock(), f.now.Format(time.RFC3339)}
+		},
+		code: CodeInvalidArgument,
+	},
+	{
+		name: "ClaimEscrow with the wrong secret", caller: "customer",
+		args: func(f *fixture) []string { return []string{"ClaimEscrow", f.escrowID, "00"} },
+		code: CodeInvalidArgument,
+	},
+	{
+		name: "ClaimEscrow after it expires", caller: "customer",
+		args:  func(f *fixture) []string { return []string{"ClaimEscrow", f.escrowID, testSecret} },
+		setup: func(f *fixture) { f.now = f.now.Add(time.Hour) },
+		code:  CodeFailedPrecondition,
+	},
+	{
+		name: "RefundEscrow before it expires", caller: "customer",
+		args: func(f *fixture) []string { return []string{"RefundEscrow", f.escrowID} },
+		code: CodeFailedPrecondition,
+	},
+	{name: "GetEscrow of a missing escrow", caller: "auditor", args: args("GetEscrow", "nope"), code: CodeNotFound},
+	{name: "CreateAccount of an existing account", caller: "teller", args: args("CreateAccount", "usd1", "0", "BankA", "USD", AccountChecking), code: CodeAlreadyExists},
+	{name: "CreateAccount with an unknown currency", caller: "teller", args: args("CreateAccount", "new", "0", "BankA", "XXX", AccountChecking), code: CodeInvalidArgument},
+	{
+		name: "InitLedger over existing accounts", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"InitLedger", jsonArg(f.t, []SeedAccount{{ID: "usd1", Balance: 100, Currency: "USD", Bank: "BankA"}}), "false"}
+		},
+		code: CodeAlreadyExists,
+	},
+	{name: "ReadAccount of a missing account", caller: "auditor", args: args("ReadAccount", "nope"), code: CodeNotFound},
+	{name: "ListTransfersForAccount of a missing account", caller: "auditor", args: args("ListTransfersForAccount", "nope", "10", ""), code: CodeNotFound},
+	{name: "GetAllTxs of a missing account", caller: "auditor", args: args("GetAllTxs", "nope"), code: CodeNotFound},
+	{
+		name: "ListTransfersForAccount of an account without transfers", caller: "auditor", args: args("ListTransfersForAccount", "eur1", "10", ""),
+		check: func(f *fixture, payload []byte) {
+			var page TransferPage
+			f.mustDecode(payload, &page)
+			if len(page.Transfers) != 0 {
+				f.t.Errorf("got %+v", page)
+			}
+		},
+	},
+	{name: "GetTransfer of a missing transfer", caller: "auditor", args: args("GetTransfer", "nope"), code: CodeNotFound},
+	{name: "CloseAccount holding a balance", caller: "admin", args: args("CloseAccount", "eur1", ""), code: CodeFailedPrecondition},
+	{name: "CloseAccount with a locked escrow", caller: "admin", args: args("CloseAccount", "usd2", "usd1"), code: CodeFailedPrecondition},
+	{
+		name: "CloseAccount sweeping a dormant account", caller: "admin", args: args("CloseAccount", "eur1", "eur2"),
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "CreateAccount", "eur2", "0", "BankA", "EUR", AccountChecking)
+			f.mustSubmit("admin", "SetAccountStatus", "eur1", StatusDormant)
+		},
+		code: CodeFailedPrecondition,
+	},
+	{
+		name: "CloseAccount sweeping above the daily limit", caller: "admin", args: args("CloseAccount", "eur1", "eur2"),
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "CreateAccount", "eur2", "0", "BankA", "EUR", AccountChecking)
+			f.mustSubmit("admin", "SetAccountLimits", "eur1", "0", "500", "0")
+		},
+		code: CodeLimitExceeded,
+	},
+	{
+		name: "CloseAccount sweeping within the limits", caller: "admin", args: args("CloseAccount", "eur1", "eur2"),
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "CreateAccount", "eur2", "0", "BankA", "EUR", AccountChecking)
+			f.mustSubmit("admin", "SetAccountLimits", "eur1", "0", "40000", "0")
+		},
+		check: func(f *fixture, payload []byte) {
+			var limits AccountLimits
+			f.mustDecode(f.mustSubmit("admin", "GetAccountLimits", "eur1"), &limits)
+			if limits.Usage == nil || limits.Usage.DailyTotal != 30000 {
+				f.t.Errorf("the sweep was not recorded in the usage: %+v", limits.Usage)
+			}
+		},
+	},
+	{
+		name: "CloseAccount sweeping without a fee", caller: "admin", args: args("CloseAccount", "eur1", "eur2"),
+		setup: func(f *fixture) {
+			f.mustSubmit("admin", "CreateAccount", "eur2", "0", "BankA", "EUR", AccountChecking)
+			f.mustSubmit("admin", "CreateAccount", "eur3", "0", "BankA", "EUR", AccountChecking)
+			f.mustSubmit("admin", "SetFeeSchedule", jsonArg(f.t, []FeeRule{{Currency: "EUR", Collector: "eur3", Flat: 100}}))
+		},
+		check: func(f *fixture, payload []byte) {
+			f.expectBalance("eur2", 30000)
+			f.expectBalance("eur3", 0)
+		},
+	},
+	{
+		name: "CloseAccount of an account of a customer", caller: "admin", args: args("CloseAccount", "cust", "usd1"),
+		check: func(f *fixture, payload []byte) {
+			if status := f.account("cust").Status; status != StatusClosed {
+				f.t.Errorf("the account is %s, want %s", status, StatusClosed)
+			}
+			f.expectBalance("cust", 0)
+			f.expectBalance("usd1", 13500)
+		},
+	},
+	{
+		name: "CloseAccount of an empty dormant account", caller: "admin", args: args("CloseAccount", "empty", ""),
+		setup: func(f *fixture) { f.mustSubmit("admin", "SetAccountStatus", "empty", StatusDormant) },
+		check: func(f *fixture, payload []byte) {
+			if status := f.account("empty").Status; status != StatusClosed {
+				f.t.Errorf("the account is %s, want %s", status, StatusClosed)
+			}
+		},
+	},
+	{name: "DeleteAccount holding a balance", caller: "admin", args: args("DeleteAccount", "eur1"), code: CodeFailedPrecondition},
+	{name: "SetAccountStatus to closed", caller: "admin", args: args("SetAccountStatus", "usd1", StatusClosed), code: CodeInvalidArgument},
+	{name: "SetFXRate with an unknown base currency", caller: "admin", args: args("SetFXRate", "XXX", "EUR", "0.9"), code: CodeInvalidArgument},
+	{name: "SetFXRate with an unknown quote currency", caller: "admin", args: args("SetFXRate", "USD", "XXX", "0.9"), code: CodeInvalidArgument},
+	{name: "SetFXRate with an invalid rate", caller: "admin", args: args("SetFXRate", "USD", "EUR", "-1"), code: CodeInvalidArgument},
+	{name: "SetFXConfig without rate setters", caller: "admin", args: args("SetFXConfig", "[]", "60"), code: CodeInvalidArgument},
+	{name: "QueryAccounts by balance without a currency", caller: "auditor", args: args("QueryAccounts", "", "", "", "1", "0", "10", ""), code: CodeInvalidArgument},
+	{name: "FreezeAccount in an unknown mode", caller: "compliance", args: args("FreezeAccount", "usd1", "partial", "AML_REVIEW"), code: CodeInvalidArgument},
+	{
+		name: "SetFeeSchedule with a collector in another currency", caller: "admin",
+		args: func(f *fixture) []string {
+			return []string{"SetFeeSchedule", jsonArg(f.t, []FeeRule{{Currency: "USD", Collector: "eur1", Flat: 100}})}
+		},
+		code: CodeFailedPrecondition,
+	},
+}
+
+func TestContractErrors(t *testing.T) {
+	run(t, errorCases)
+}
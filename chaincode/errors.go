@@ -0,0 +1,75 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode is the class of an error returned by the contract functions. The codes are stable,
+// while the messages that go with them may change.
+type ErrorCode string
+
+// Codes of the errors returned by the contract functions. Errors without a code are unexpected
+// failures of the contract or the peer.
+const (
+	// CodeInvalidArgument is an argument that is malformed or out of range.
+	CodeInvalidArgument ErrorCode = "INVALID_ARGUMENT"
+	// CodeNotFound is a missing account, transfer or exchange rate.
+	CodeNotFound ErrorCode = "NOT_FOUND"
+	// CodeAlreadyExists is an account that already exists.
+	CodeAlreadyExists ErrorCode = "ALREADY_EXISTS"
+	// CodeInsufficientFunds is a source account without enough balance.
+	CodeInsufficientFunds ErrorCode = "INSUFFICIENT_FUNDS"
+	// CodeAccountFrozen is an account whose freeze stops the money movement asked for.
+	CodeAccountFrozen ErrorCode = "ACCOUNT_FROZEN"
+	// CodeLimitExceeded is a transfer above the per-transfer, daily or monthly limit of its source
+	// account.
+	CodeLimitExceeded ErrorCode = "LIMIT_EXCEEDED"
+	// CodeUnauthorized is a client that is not allowed to do what it asked.
+	CodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	// CodeFailedPrecondition is a request that the state of the ledger does not allow, such as a
+	// transfer between accounts in different currencies or with a stale exchange rate.
+	CodeFailedPrecondition ErrorCode = "FAILED_PRECONDITION"
+)
+
+// Error is an error returned by a contract function, with its code. Fabric only passes the message
+// of an error to the client, so the message of an Error is its JSON encoding, which ParseError
+// decodes.
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+func (e *Error) Error() string {
+	errorJSON, err := json.Marshal(e)
+	if err != nil {
+		return e.Message
+	}
+	return string(errorJSON)
+}
+
+// Is reports whether the target is an Error with the same code, so that
+// errors.Is(err, &chaincode.Error{Code: chaincode.CodeNotFound}) matches any missing object.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// ParseError decodes the message of an error returned by a contract function. It returns false
+// for the messages of errors without a code, including those of older versions of the contract.
+func ParseError(message string) (*Error, bool) {
+	if !strings.HasPrefix(message, "{") {
+		return nil, false
+	}
+	var e Error
+	if err := json.Unmarshal([]byte(message), &e); err != nil || e.Code == "" {
+		return nil, false
+	}
+	return &e, true
+}
+
+// newError returns an Error with the given code and message.
+func newError(code ErrorCode, format string, args ...interface{}) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,475 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Escrows are stored under the composite key escrow/[id], where the ID of an escrow is the ID of the
+// transaction that created it. Locked escrows are indexed by their payer and payee under
+// escrow~account/[account ID, id], so that accounts with locked escrows are not closed. The
+// transaction refunding an escrow refers to it under escrowrefund/[tx ID], so that the statement of
+// the payer describes the refund.
+const (
+	escrowObjectType       = "escrow"
+	escrowByAccountIndex   = "escrow~account"
+	escrowRefundObjectType = "escrowrefund"
+)
+
+// Statuses of an escrow.
+const (
+	// EscrowLocked is an escrow holding the funds of the payer until it is claimed or refunded.
+	EscrowLocked = "locked"
+	// EscrowClaimed is an escrow whose funds were released to the payee.
+	EscrowClaimed = "claimed"
+	// EscrowRefunded is an escrow whose funds were returned to the payer after it expired.
+	EscrowRefunded = "refunded"
+)
+
+// Escrow is a hash time-locked payment. Its amount, in minor units of Currency, is debited from the
+// payer account FromID when the escrow is created, and released to the payee account ToID by whoever
+// presents the preimage of Hashlock, the hex encoded SHA-256 hash of a secret, before Expiry. After
+// Expiry, the amount can only be refunded to the payer. Preimage is revealed by the claim, and
+// SettledAt and SettleTxID are those of the claim or refund, zero and empty until then.
+type Escrow struct {
+	ID         string    `json:"ID"`
+	FromID     string    `json:"FromID"`
+	ToID       string    `json:"ToID"`
+	Amount     Amount    `json:"Amount"`
+	Currency   string    `json:"Currency"`
+	Hashlock   string    `json:"Hashlock"`
+	Expiry     time.Time `json:"Expiry"`
+	Status     string    `json:"Status"`
+	Initiator  string    `json:"Initiator"`
+	CreatedAt  time.Time `json:"CreatedAt"`
+	Preimage   string    `json:"Preimage,omitempty" metadata:"Preimage,optional"`
+	SettledAt  time.Time `json:"SettledAt"`
+	SettleTxID string    `json:"SettleTxID,omitempty" metadata:"SettleTxID,optional"`
+}
+
+// CreateEscrow locks the given amount, expressed in minor units, of the payer account in an escrow
+// for the payee account, until the hex encoded preimage of the hashlock claims it or it expires at
+// the given RFC 3339 time. Both accounts must hold the same currency, only the owner of the payer
+// account can create it, and the amount counts towards the limits of the payer. Escrows are free of
+// fees.
+func (s *SmartContract) CreateEscrow(ctx contractapi.TransactionContextInterface, fromId, toId string, amount int64, hashlock, expiry string) (*Escrow, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "CreateEscrow")
+	if err != nil {
+		return nil, err
+	}
+
+	if amount <= 0 {
+		return nil, newError(CodeInvalidArgument, "amount must be positive")
+	}
+	if fromId == toId {
+		return nil, newError(CodeInvalidArgument, "the payer and payee accounts must differ")
+	}
+	hashlock = strings.ToLower(hashlock)
+	if decoded, err := hex.DecodeString(hashlock); err != nil || len(decoded) != sha256.Size {
+		return nil, newError(CodeInvalidArgument, "the hashlock must be a hex encoded SHA-256 hash")
+	}
+	expiryTime, err := time.Parse(time.RFC3339, expiry)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "invalid expiry: %v", err)
+	}
+	timestamp, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !expiryTime.After(timestamp) {
+		return nil, newError(CodeInvalidArgument, "the escrow must expire after it is created")
+	}
+
+	fromAcc, err := getExistingAccount(ctx, fromId)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientIsAccountOwner(ctx, fromAcc)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyCanDebit(fromAcc)
+	if err != nil {
+		return nil, err
+	}
+
+	toAcc, err := getExistingAccount(ctx, toId)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyCanCredit(toAcc)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAcc.Currency != toAcc.Currency {
+		return nil, newError(CodeFailedPrecondition, "cannot create an escrow between accounts in different currencies (%s and %s)", fromAcc.Currency, toAcc.Currency)
+	}
+	if fromAcc.Balance < Amount(amount) {
+		return nil, newError(CodeInsufficientFunds, "the source account does not have enough balance")
+	}
+	err = verifyDebitLimits(ctx, fromAcc, Amount(amount))
+	if err != nil {
+		return nil, err
+	}
+
+	initiator, err := getClientID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	escrow := &Escrow{
+		ID:        ctx.GetStub().GetTxID(),
+		FromID:    fromId,
+		ToID:      toId,
+		Amount:    Amount(amount),
+		Currency:  fromAcc.Currency,
+		Hashlock:  hashlock,
+		Expiry:    expiryTime.UTC(),
+		Status:    EscrowLocked,
+		Initiator: initiator,
+		CreatedAt: timestamp,
+	}
+
+	fromAcc.Balance -= Amount(amount)
+	if err := putAccount(ctx, fromAcc); err != nil {
+		return nil, err
+	}
+	if err := putEscrow(ctx, escrow); err != nil {
+		return nil, err
+	}
+	for _, accountID := range []string{fromId, toId} {
+		indexKey, err := ctx.GetStub().CreateCompositeKey(escrowByAccountIndex, []string{accountID, escrow.ID})
+		if err != nil {
+			return nil, err
+		}
+		err = ctx.GetStub().PutState(indexKey, indexValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to put to world state. %v", err)
+		}
+	}
+
+	err = emitEvent(ctx, EventEscrowCreated, newEscrowEvent(escrow))
+	if err != nil {
+		return nil, err
+	}
+
+	return escrow, nil
+}
+
+// ClaimEscrow releases the funds of a locked escrow to its payee, given the hex encoded preimage of
+// its hashlock before it expires. Anyone knowing the preimage can claim it. The payment is recorded
+// as a transfer from the payer to the payee, and the preimage is revealed in the escrow.
+func (s *SmartContract) ClaimEscrow(ctx contractapi.TransactionContextInterface, escrowID, preimage string) (*Escrow, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "ClaimEscrow")
+	if err != nil {
+		return nil, err
+	}
+
+	escrow, err := getLockedEscrow(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !timestamp.Before(escrow.Expiry) {
+		return nil, newError(CodeFailedPrecondition, "the escrow %s expired at %s and can only be refunded", escrowID, escrow.Expiry.Format(time.RFC3339))
+	}
+	secret, err := hex.DecodeString(preimage)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "the preimage must be hex encoded")
+	}
+	hash := sha256.Sum256(secret)
+	if hex.EncodeToString(hash[:]) != escrow.Hashlock {
+		return nil, newError(CodeInvalidArgument, "the preimage does not match the hashlock of the escrow %s", escrowID)
+	}
+
+	fromAcc, err := getExistingAccount(ctx, escrow.FromID)
+	if err != nil {
+		return nil, err
+	}
+	toAcc, err := getExistingAccount(ctx, escrow.ToID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyCanCredit(toAcc)
+	if err != nil {
+		return nil, err
+	}
+	toBalance, err := addAmounts(toAcc.Balance, escrow.Amount)
+	if err != nil {
+		return nil, newError(CodeFailedPrecondition, "the payee account cannot hold the amount: %v", err)
+	}
+
+	toAcc.Balance = toBalance
+	if err := putAccount(ctx, toAcc); err != nil {
+		return nil, err
+	}
+	transfer, err := newTransferRecord(ctx, fromAcc, toAcc, escrow.Amount, escrow.Amount, escrowMemo(escrow))
+	if err != nil {
+		return nil, err
+	}
+	if err := putTransfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	escrow.Preimage = strings.ToLower(preimage)
+	err = settleEscrow(ctx, escrow, EscrowClaimed, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitEvent(ctx, EventEscrowClaimed, newEscrowEvent(escrow))
+	if err != nil {
+		return nil, err
+	}
+
+	return escrow, nil
+}
+
+// RefundEscrow returns the funds of a locked escrow to its payer once it has expired. Anyone can
+// refund an expired escrow, since the funds can only go back to the payer.
+func (s *SmartContract) RefundEscrow(ctx contractapi.TransactionContextInterface, escrowID string) (*Escrow, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "RefundEscrow")
+	if err != nil {
+		return nil, err
+	}
+
+	escrow, err := getLockedEscrow(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp.Before(escrow.Expiry) {
+		return nil, newError(CodeFailedPrecondition, "the escrow %s does not expire until %s", escrowID, escrow.Expiry.Format(time.RFC3339))
+	}
+
+	fromAcc, err := getExistingAccount(ctx, escrow.FromID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyCanCredit(fromAcc)
+	if err != nil {
+		return nil, err
+	}
+	fromBalance, err := addAmounts(fromAcc.Balance, escrow.Amount)
+	if err != nil {
+		return nil, newError(CodeFailedPrecondition, "the payer account cannot hold the amount: %v", err)
+	}
+
+	fromAcc.Balance = fromBalance
+	if err := putAccount(ctx, fromAcc); err != nil {
+		return nil, err
+	}
+	refundKey, err := ctx.GetStub().CreateCompositeKey(escrowRefundObjectType, []string{ctx.GetStub().GetTxID()})
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(refundKey, []byte(escrow.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	err = settleEscrow(ctx, escrow, EscrowRefunded, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	err = emitEvent(ctx, EventEscrowRefunded, newEscrowEvent(escrow))
+	if err != nil {
+		return nil, err
+	}
+
+	return escrow, nil
+}
+
+// GetEscrow returns the escrow with the given ID.
+func (s *SmartContract) GetEscrow(ctx contractapi.TransactionContextInterface, escrowID string) (*Escrow, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "GetEscrow")
+	if err != nil {
+		return nil, err
+	}
+
+	escrow, err := getEscrow(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	if escrow == nil {
+		return nil, newError(CodeNotFound, "the escrow %s does not exist", escrowID)
+	}
+
+	return escrow, nil
+}
+
+// getEscrow reads the escrow with the given ID from the world state. It returns nil when the escrow
+// does not exist.
+func getEscrow(ctx contractapi.TransactionContextInterface, escrowID string) (*Escrow, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(escrowObjectType, []string{escrowID})
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "invalid escrow ID %q: %v", escrowID, err)
+	}
+	escrowJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if escrowJSON == nil {
+		return nil, nil
+	}
+
+	var escrow Escrow
+	err = json.Unmarshal(escrowJSON, &escrow)
+	if err != nil {
+		return nil, err
+	}
+	return &escrow, nil
+}
+
+// getLockedEscrow returns the escrow with the given ID, which must exist and be locked.
+func getLockedEscrow(ctx contractapi.TransactionContextInterface, escrowID string) (*Escrow, error) {
+	escrow, err := getEscrow(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	if escrow == nil {
+		return nil, newError(CodeNotFound, "the escrow %s does not exist", escrowID)
+	}
+	if escrow.Status != EscrowLocked {
+		return nil, newError(CodeFailedPrecondition, "the escrow %s was already %s", escrowID, escrow.Status)
+	}
+	return escrow, nil
+}
+
+// putEscrow writes the escrow to the world state.
+func putEscrow(ctx contractapi.TransactionContextInterface, escrow *Escrow) error {
+	key, err := ctx.GetStub().CreateCompositeKey(escrowObjectType, []string{escrow.ID})
+	if err != nil {
+		return fmt.Errorf("invalid escrow ID %q: %v", escrow.ID, err)
+	}
+	escrowJSON, err := json.Marshal(escrow)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, escrowJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
+
+// settleEscrow records the claim or refund of the escrow made by the current transaction, removing
+// it from the index of locked escrows.
+func settleEscrow(ctx contractapi.TransactionContextInterface, escrow *Escrow, status string, timestamp time.Time) error {
+	for _, accountID := range []string{escrow.FromID, escrow.ToID} {
+		indexKey, err := ctx.GetStub().CreateCompositeKey(escrowByAccountIndex, []string{accountID, escrow.ID})
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().DelState(indexKey)
+		if err != nil {
+			return fmt.Errorf("failed to delete from world state: %v", err)
+		}
+	}
+
+	escrow.Status = status
+	escrow.SettledAt = timestamp
+	escrow.SettleTxID = ctx.GetStub().GetTxID()
+	return putEscrow(ctx, escrow)
+}
+
+// getRefundedEscrow returns the escrow refunded by the transaction with the given ID, or nil when
+// the transaction refunded none.
+func getRefundedEscrow(ctx contractapi.TransactionContextInterface, txID string) (*Escrow, error) {
+	refundKey, err := ctx.GetStub().CreateCompositeKey(escrowRefundObjectType, []string{txID})
+	if err != nil {
+		return nil, err
+	}
+	escrowID, err := ctx.GetStub().GetState(refundKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if escrowID == nil {
+		return nil, nil
+	}
+	return getEscrow(ctx, string(escrowID))
+}
+
+// verifyNoLockedEscrows checks that the account is neither the payer nor the payee of a locked
+// escrow.
+func verifyNoLockedEscrows(ctx contractapi.TransactionContextInterface, account *Account) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(escrowByAccountIndex, []string{account.ID})
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	if !resultsIterator.HasNext() {
+		return nil
+	}
+	result, err := resultsIterator.Next()
+	if err != nil {
+		return err
+	}
+	_, attributes, err := ctx.GetStub().SplitCompositeKey(result.Key)
+	if err != nil {
+		return err
+	}
+	if len(attributes) != 2 {
+		return fmt.Errorf("the key %q is not an escrow index entry", result.Key)
+	}
+	return newError(CodeFailedPrecondition, "the account %s is involved in the locked escrow %s", account.ID, attributes[1])
+}
+
+// escrowMemo is the memo of the transfer paying the escrow to its payee.
+func escrowMemo(escrow *Escrow) string {
+	return "escrow " + escrow.ID
+}
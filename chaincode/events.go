@@ -0,0 +1,151 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Names of the chaincode events emitted by the contract. Fabric keeps a single event per
+// transaction, so every function emits at most one of them.
+const (
+	EventAccountCreated       = "AccountCreated"
+	EventAccountClosed        = "AccountClosed"
+	EventAccountStatusChanged = "AccountStatusChanged"
+	EventFundsTransferred     = "FundsTransferred"
+	EventBatchTransferred     = "BatchTransferred"
+	EventAccountFrozen        = "AccountFrozen"
+	EventAccountUnfrozen      = "AccountUnfrozen"
+	EventEscrowCreated        = "EscrowCreated"
+	EventEscrowClaimed        = "EscrowClaimed"
+	EventEscrowRefunded       = "EscrowRefunded"
+	// EventAccountDeleted was emitted by older versions of the contract, which removed deleted
+	// accounts from the world state instead of closing them.
+	EventAccountDeleted = "AccountDeleted"
+)
+
+// AccountEvent is the payload of the AccountCreated and AccountDeleted events.
+type AccountEvent struct {
+	AccountID string `json:"AccountID"`
+	Bank      string `json:"Bank"`
+	Currency  string `json:"Currency"`
+	Balance   Amount `json:"Balance"`
+	Owner     string `json:"Owner"`
+	Type      string `json:"Type,omitempty" metadata:"Type,optional"`
+}
+
+// TransferEvent is the payload of the FundsTransferred event. Amount is debited from the source
+// account in its currency and ToAmount is credited to the destination account in its currency;
+// they only differ for transfers with conversion. TransferID is the ID of the transfer record.
+type TransferEvent struct {
+	TransferID  string `json:"TransferID"`
+	FromID      string `json:"FromID"`
+	ToID        string `json:"ToID"`
+	Amount      Amount `json:"Amount"`
+	Currency    string `json:"Currency"`
+	ToAmount    Amount `json:"ToAmount"`
+	ToCurrency  string `json:"ToCurrency"`
+	FromBalance Amount `json:"FromBalance"`
+	ToBalance   Amount `json:"ToBalance"`
+	// Fee is the fee charged to the source account on top of Amount, credited to FeeCollector.
+	Fee          Amount `json:"Fee,omitempty" metadata:"Fee,optional"`
+	FeeCollector string `json:"FeeCollector,omitempty" metadata:"FeeCollector,optional"`
+}
+
+// BatchTransferEvent is the payload of the BatchTransferred event, with the transfers of the batch
+// in its order. The balances of each transfer are those its accounts were left with right after it.
+type BatchTransferEvent struct {
+	Transfers []*TransferEvent `json:"Transfers"`
+}
+
+// ClosureEvent is the payload of the AccountClosed event. When the balance of the account was swept
+// to another account, SweptTo is that account, SweptAmount the balance and TransferID the ID of the
+// transfer record.
+type ClosureEvent struct {
+	AccountID   string `json:"AccountID"`
+	Bank        string `json:"Bank"`
+	Currency    string `json:"Currency"`
+	SweptTo     string `json:"SweptTo,omitempty"`
+	SweptAmount Amount `json:"SweptAmount,omitempty"`
+	TransferID  string `json:"TransferID,omitempty"`
+}
+
+// StatusEvent is the payload of the AccountStatusChanged event.
+type StatusEvent struct {
+	AccountID string `json:"AccountID"`
+	From      string `json:"From"`
+	To        string `json:"To"`
+}
+
+// FreezeEvent is the payload of the AccountFrozen and AccountUnfrozen events. Mode and Reason are
+// those of the freeze set or lifted, and By is the ID of the client that set or lifted it.
+type FreezeEvent struct {
+	AccountID string `json:"AccountID"`
+	Mode      string `json:"Mode"`
+	Reason    string `json:"Reason"`
+	By        string `json:"By"`
+}
+
+// EscrowEvent is the payload of the EscrowCreated, EscrowClaimed and EscrowRefunded events. Preimage
+// is only set when the escrow is claimed.
+type EscrowEvent struct {
+	EscrowID string    `json:"EscrowID"`
+	FromID   string    `json:"FromID"`
+	ToID     string    `json:"ToID"`
+	Amount   Amount    `json:"Amount"`
+	Currency string    `json:"Currency"`
+	Expiry   time.Time `json:"Expiry"`
+	Preimage string    `json:"Preimage,omitempty" metadata:"Preimage,optional"`
+}
+
+// newEscrowEvent returns the event payload describing the given escrow.
+func newEscrowEvent(escrow *Escrow) *EscrowEvent {
+	return &EscrowEvent{
+		EscrowID: escrow.ID,
+		FromID:   escrow.FromID,
+		ToID:     escrow.ToID,
+		Amount:   escrow.Amount,
+		Currency: escrow.Currency,
+		Expiry:   escrow.Expiry,
+		Preimage: escrow.Preimage,
+	}
+}
+
+// newFreezeEvent returns the event payload describing the freeze of the given account.
+func newFreezeEvent(account *Account, clientID string) *FreezeEvent {
+	return &FreezeEvent{
+		AccountID: account.ID,
+		Mode:      account.Freeze.Mode,
+		Reason:    account.Freeze.Reason,
+		By:        clientID,
+	}
+}
+
+// newAccountEvent returns the event payload describing the given account.
+func newAccountEvent(account *Account) *AccountEvent {
+	return &AccountEvent{
+		AccountID: account.ID,
+		Bank:      account.Bank,
+		Currency:  account.Currency,
+		Balance:   account.Balance,
+		Owner:     account.Owner,
+		Type:      account.Type,
+	}
+}
+
+// emitEvent sets the chaincode event of the transaction with the JSON encoded payload.
+func emitEvent(ctx contractapi.TransactionContextInterface, name string, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().SetEvent(name, payloadJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set %s event: %v", name, err)
+	}
+
+	return nil
+}
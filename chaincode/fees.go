@@ -0,0 +1,336 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// feesConfigName is the name of the fee schedule under the config composite key.
+	feesConfigName = "fees"
+	// maxFeeRules is the maximum number of rules of the fee schedule.
+	maxFeeRules = 100
+)
+
+// FeeSchedule is the schedule of the fees charged on transfers. Its rules are checked in order, and
+// the first one matching the source account and the currency of a transfer sets its fee. Transfers
+// that match no rule are free.
+type FeeSchedule struct {
+	Rules []FeeRule `json:"Rules"`
+}
+
+// FeeRule is a rule of the fee schedule. It matches the transfers in its currency from accounts of
+// its bank and type; an empty bank or type matches any. The fee is Flat plus Percent of the amount,
+// rounded down to the minor unit, and then raised to Min and lowered to Max when it is beyond them.
+// When the rule has tiers, the last tier starting at or below the amount sets Flat and Percent
+// instead. Amounts are expressed in minor units of the currency, Percent is a decimal string such as
+// "0.5", and a zero Max is no maximum. The fee is credited to the Collector account, which must hold
+// the currency of the rule; transfers from the collector itself are free.
+type FeeRule struct {
+	Bank        string    `json:"Bank,omitempty" metadata:"Bank,optional"`
+	AccountType string    `json:"AccountType,omitempty" metadata:"AccountType,optional"`
+	Currency    string    `json:"Currency"`
+	Collector   string    `json:"Collector"`
+	Flat        Amount    `json:"Flat"`
+	Percent     string    `json:"Percent,omitempty" metadata:"Percent,optional"`
+	Tiers       []FeeTier `json:"Tiers,omitempty" metadata:"Tiers,optional"`
+	Min         Amount    `json:"Min"`
+	Max         Amount    `json:"Max"`
+}
+
+// FeeTier is a tier of a fee rule, applying to the amounts from From upwards.
+type FeeTier struct {
+	From    Amount `json:"From"`
+	Flat    Amount `json:"Flat"`
+	Percent string `json:"Percent,omitempty" metadata:"Percent,optional"`
+}
+
+// FeeQuote is the fee a transfer would be charged. Total is the amount debited from the source
+// account, the amount of the transfer plus its fee, and FeeCollector is empty when there is no fee.
+type FeeQuote struct {
+	FromID       string `json:"FromID"`
+	ToID         string `json:"ToID"`
+	Amount       Amount `json:"Amount"`
+	Fee          Amount `json:"Fee"`
+	Total        Amount `json:"Total"`
+	Currency     string `json:"Currency"`
+	FeeCollector string `json:"FeeCollector,omitempty" metadata:"FeeCollector,optional"`
+}
+
+// SetFeeSchedule replaces the rules of the fee schedule. An empty list of rules stops charging fees.
+func (s *SmartContract) SetFeeSchedule(ctx contractapi.TransactionContextInterface, rules []FeeRule) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "SetFeeSchedule")
+	if err != nil {
+		return err
+	}
+
+	if len(rules) > maxFeeRules {
+		return newError(CodeInvalidArgument, "the fee schedule has %d rules, the maximum is %d", len(rules), maxFeeRules)
+	}
+	for i, rule := range rules {
+		err = verifyFeeRule(ctx, rule)
+		if err != nil {
+			contractErr, ok := err.(*Error)
+			if !ok {
+				return err
+			}
+			return newError(contractErr.Code, "the fee rule at index %d is invalid: %s", i, contractErr.Message)
+		}
+	}
+
+	if rules == nil {
+		rules = []FeeRule{}
+	}
+	scheduleJSON, err := json.Marshal(FeeSchedule{Rules: rules})
+	if err != nil {
+		return err
+	}
+	scheduleKey, err := ctx.GetStub().CreateCompositeKey(configObjectType, []string{feesConfigName})
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(scheduleKey, scheduleJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	return nil
+}
+
+// GetFeeSchedule returns the fee schedule in use.
+func (s *SmartContract) GetFeeSchedule(ctx contractapi.TransactionContextInterface) (*FeeSchedule, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "GetFeeSchedule")
+	if err != nil {
+		return nil, err
+	}
+
+	return getFeeSchedule(ctx)
+}
+
+// QuoteTransfer returns the fee that Transfer would charge for the given amount, expressed in minor
+// units, from one account to another, without making the transfer.
+func (s *SmartContract) QuoteTransfer(ctx contractapi.TransactionContextInterface, fromId, toId string, amount int64) (*FeeQuote, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "QuoteTransfer")
+	if err != nil {
+		return nil, err
+	}
+
+	if amount <= 0 {
+		return nil, newError(CodeInvalidArgument, "amount must be positive")
+	}
+	if fromId == toId {
+		return nil, newError(CodeInvalidArgument, "the source and destination accounts must differ")
+	}
+	fromAcc, err := getExistingAccount(ctx, fromId)
+	if err != nil {
+		return nil, err
+	}
+	toAcc, err := getExistingAccount(ctx, toId)
+	if err != nil {
+		return nil, err
+	}
+	if fromAcc.Currency != toAcc.Currency {
+		return nil, newError(CodeFailedPrecondition, "cannot transfer between accounts in different currencies (%s and %s), use TransferWithConversion instead", fromAcc.Currency, toAcc.Currency)
+	}
+
+	fee, collector, err := getTransferFee(ctx, fromAcc, Amount(amount))
+	if err != nil {
+		return nil, err
+	}
+	total, err := addAmounts(Amount(amount), fee)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "the amount plus its fee is out of range")
+	}
+
+	return &FeeQuote{
+		FromID:       fromId,
+		ToID:         toId,
+		Amount:       Amount(amount),
+		Fee:          fee,
+		Total:        total,
+		Currency:     fromAcc.Currency,
+		FeeCollector: collector,
+	}, nil
+}
+
+// getFeeSchedule returns the stored fee schedule, which has no rules until SetFeeSchedule is called.
+func getFeeSchedule(ctx contractapi.TransactionContextInterface) (*FeeSchedule, error) {
+	scheduleKey, err := ctx.GetStub().CreateCompositeKey(configObjectType, []string{feesConfigName})
+	if err != nil {
+		return nil, err
+	}
+	scheduleJSON, err := ctx.GetStub().GetState(scheduleKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if scheduleJSON == nil {
+		return &FeeSchedule{Rules: []FeeRule{}}, nil
+	}
+
+	var schedule FeeSchedule
+	err = json.Unmarshal(scheduleJSON, &schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// getTransferFee returns the fee of a transfer of the given amount from the given account, and the
+// ID of the account it is credited to. The fee is zero, with no collector, when no rule matches.
+func getTransferFee(ctx contractapi.TransactionContextInterface, fromAcc *Account, amount Amount) (Amount, string, error) {
+	schedule, err := getFeeSchedule(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	for _, rule := range schedule.Rules {
+		if rule.Currency != fromAcc.Currency ||
+			rule.Bank != "" && rule.Bank != fromAcc.Bank ||
+			rule.AccountType != "" && rule.AccountType != fromAcc.Type {
+			continue
+		}
+		if rule.Collector == fromAcc.ID {
+			return 0, "", nil
+		}
+		fee, err := rule.fee(amount)
+		if err != nil {
+			return 0, "", err
+		}
+		if fee == 0 {
+			return 0, "", nil
+		}
+		return fee, rule.Collector, nil
+	}
+	return 0, "", nil
+}
+
+// fee returns the fee the rule charges on the given amount.
+func (rule FeeRule) fee(amount Amount) (Amount, error) {
+	flat, percent := rule.Flat, rule.Percent
+	for _, tier := range rule.Tiers {
+		if tier.From > amount {
+			break
+		}
+		flat, percent = tier.Flat, tier.Percent
+	}
+
+	fee := flat
+	if percent != "" {
+		p, err := parseFeePercent(percent)
+		if err != nil {
+			return 0, err
+		}
+		variable := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(amount)), p)
+		variable.Quo(variable, new(big.Rat).SetInt64(100))
+		minor := new(big.Int).Quo(variable.Num(), variable.Denom())
+		if !minor.IsInt64() {
+			return 0, newError(CodeInvalidArgument, "the fee is out of range")
+		}
+		fee, err = addAmounts(fee, Amount(minor.Int64()))
+		if err != nil {
+			return 0, newError(CodeInvalidArgument, "the fee is out of range")
+		}
+	}
+
+	if fee < rule.Min {
+		fee = rule.Min
+	}
+	if rule.Max > 0 && fee > rule.Max {
+		fee = rule.Max
+	}
+	return fee, nil
+}
+
+// verifyFeeRule checks that a fee rule is well formed and that its collector account can receive
+// its fees.
+func verifyFeeRule(ctx contractapi.TransactionContextInterface, rule FeeRule) error {
+	if _, err := CurrencyDigits(rule.Currency); err != nil {
+		return newError(CodeInvalidArgument, "%v", err)
+	}
+	if rule.AccountType != "" {
+		if err := verifyAccountType(rule.AccountType); err != nil {
+			return newError(CodeInvalidArgument, "%v", err)
+		}
+	}
+	if rule.Flat < 0 || rule.Min < 0 || rule.Max < 0 {
+		return newError(CodeInvalidArgument, "fee amounts must not be negative")
+	}
+	if rule.Max > 0 && rule.Max < rule.Min {
+		return newError(CodeInvalidArgument, "the maximum fee must not be below the minimum fee")
+	}
+	if rule.Percent != "" {
+		if _, err := parseFeePercent(rule.Percent); err != nil {
+			return err
+		}
+	}
+	for i, tier := range rule.Tiers {
+		if tier.From < 0 || tier.Flat < 0 {
+			return newError(CodeInvalidArgument, "fee amounts must not be negative")
+		}
+		if i > 0 && tier.From <= rule.Tiers[i-1].From {
+			return newError(CodeInvalidArgument, "the tiers must start at increasing amounts")
+		}
+		if tier.Percent != "" {
+			if _, err := parseFeePercent(tier.Percent); err != nil {
+				return err
+			}
+		}
+	}
+
+	if rule.Collector == "" {
+		return newError(CodeInvalidArgument, "a collector account is required")
+	}
+	collector, err := getExistingAccount(ctx, rule.Collector)
+	if err != nil {
+		return err
+	}
+	err = verifyNotClosed(collector)
+	if err != nil {
+		return err
+	}
+	if collector.Currency != rule.Currency {
+		return newError(CodeFailedPrecondition, "the collector account %s holds %s, not %s", collector.ID, collector.Currency, rule.Currency)
+	}
+	return nil
+}
+
+// parseFeePercent parses the percentage of a fee, a decimal string from 0 to 100.
+func parseFeePercent(percent string) (*big.Rat, error) {
+	p, ok := new(big.Rat).SetString(percent)
+	if !ok || p.Sign() < 0 || p.Cmp(big.NewRat(100, 1)) > 0 {
+		return nil, newError(CodeInvalidArgument, "invalid fee percentage %q, it must be a decimal from 0 to 100", percent)
+	}
+	return p, nil
+}
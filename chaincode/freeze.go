@@ -0,0 +1,132 @@
+package chaincode
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Modes of an account freeze.
+const (
+	// FreezeDebit stops debits from the account, which can still be credited.
+	FreezeDebit = "debit"
+	// FreezeCredit stops credits to the account, which can still be debited.
+	FreezeCredit = "credit"
+	// FreezeFull stops both debits and credits.
+	FreezeFull = "full"
+)
+
+// maxFreezeReasonLength is the maximum length, in bytes, of the reason code of a freeze.
+const maxFreezeReasonLength = 64
+
+// AccountFreeze is a compliance hold on an account, stopping the money movements of its mode.
+// Reason is a code given by compliance, such as AML_REVIEW, and FrozenBy is the ID of the client
+// that froze the account.
+type AccountFreeze struct {
+	Mode     string    `json:"Mode"`
+	Reason   string    `json:"Reason"`
+	FrozenBy string    `json:"FrozenBy"`
+	FrozenAt time.Time `json:"FrozenAt"`
+	TxID     string    `json:"TxID"`
+}
+
+// FreezeAccount freezes an account in the given mode, debit, credit or full, recording the reason
+// code and the invoking client. Freezing a frozen account replaces its freeze.
+func (s *SmartContract) FreezeAccount(ctx contractapi.TransactionContextInterface, accountID, mode, reason string) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "FreezeAccount")
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case FreezeDebit, FreezeCredit, FreezeFull:
+	default:
+		return newError(CodeInvalidArgument, "invalid freeze mode %q, it must be %s, %s or %s", mode, FreezeDebit, FreezeCredit, FreezeFull)
+	}
+	if reason == "" {
+		return newError(CodeInvalidArgument, "a reason code is required to freeze an account")
+	}
+	if len(reason) > maxFreezeReasonLength {
+		return newError(CodeInvalidArgument, "the reason code is %d bytes long, the maximum is %d", len(reason), maxFreezeReasonLength)
+	}
+
+	account, err := getExistingAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	err = verifyNotClosed(account)
+	if err != nil {
+		return err
+	}
+	clientID, err := getClientID(ctx)
+	if err != nil {
+		return err
+	}
+	timestamp, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	account.Freeze = &AccountFreeze{
+		Mode:     mode,
+		Reason:   reason,
+		FrozenBy: clientID,
+		FrozenAt: timestamp,
+		TxID:     ctx.GetStub().GetTxID(),
+	}
+	err = putAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, EventAccountFrozen, newFreezeEvent(account, clientID))
+}
+
+// UnfreezeAccount lifts the freeze of an account.
+func (s *SmartContract) UnfreezeAccount(ctx contractapi.TransactionContextInterface, accountID string) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "UnfreezeAccount")
+	if err != nil {
+		return err
+	}
+
+	account, err := getExistingAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.Freeze == nil {
+		return newError(CodeFailedPrecondition, "the account %s is not frozen", accountID)
+	}
+	clientID, err := getClientID(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := newFreezeEvent(account, clientID)
+	account.Freeze = nil
+	err = putAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, EventAccountUnfrozen, event)
+}
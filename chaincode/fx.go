@@ -0,0 +1,517 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	fxRateObjectType     = "fxrate"
+	conversionObjectType = "conversion"
+	configObjectType     = "config"
+	fxConfigName         = "fx"
+)
+
+// Defaults used until SetFXConfig stores an FX configuration on the ledger.
+var (
+	defaultRateSetters = []string{"Org1MSP"}
+	defaultMaxRateAge  = int64(time.Hour / time.Second)
+)
+
+// FXRate is an exchange rate stored on the ledger: one unit of Base is worth Rate units of Quote.
+type FXRate struct {
+	Base      string    `json:"Base"`
+	Quote     string    `json:"Quote"`
+	Rate      string    `json:"Rate"`
+	SetBy     string    `json:"SetBy"`
+	UpdatedAt time.Time `json:"UpdatedAt"`
+}
+
+// FXConfig holds the orgs allowed to set exchange rates and the maximum age, in seconds,
+// of a rate used by TransferWithConversion.
+type FXConfig struct {
+	RateSetters []string `json:"RateSetters"`
+	MaxRateAge  int64    `json:"MaxRateAge"`
+}
+
+// Conversion records both legs of a cross-currency transfer and the rate applied to it. Fee is
+// debited from the source account, in its currency, on top of FromAmount and credited to the
+// FeeCollector account; both are empty for free transfers.
+type Conversion struct {
+	TxID          string    `json:"TxID"`
+	FromID        string    `json:"FromID"`
+	FromAmount    Amount    `json:"FromAmount"`
+	FromCurrency  string    `json:"FromCurrency"`
+	ToID          string    `json:"ToID"`
+	ToAmount      Amount    `json:"ToAmount"`
+	ToCurrency    string    `json:"ToCurrency"`
+	Rate          string    `json:"Rate"`
+	RateUpdatedAt time.Time `json:"RateUpdatedAt"`
+	Timestamp     time.Time `json:"Timestamp"`
+	Fee           Amount    `json:"Fee,omitempty" metadata:"Fee,optional"`
+	FeeCollector  string    `json:"FeeCollector,omitempty" metadata:"FeeCollector,optional"`
+}
+
+// SetFXConfig replaces the FX configuration. Only a current rate setter org may change it.
+func (s *SmartContract) SetFXConfig(ctx contractapi.TransactionContextInterface, rateSetters []string, maxRateAge int64) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "SetFXConfig")
+	if err != nil {
+		return err
+	}
+
+	config, err := getFXConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !containsString(config.RateSetters, clientOrgID) {
+		return newError(CodeUnauthorized, "client from org %s is not authorized to change the FX configuration", clientOrgID)
+	}
+
+	if len(rateSetters) == 0 {
+		return newError(CodeInvalidArgument, "at least one rate setter org is required")
+	}
+	if maxRateAge <= 0 {
+		return newError(CodeInvalidArgument, "the maximum rate age must be positive")
+	}
+
+	configJSON, err := json.Marshal(FXConfig{RateSetters: rateSetters, MaxRateAge: maxRateAge})
+	if err != nil {
+		return err
+	}
+	configKey, err := ctx.GetStub().CreateCompositeKey(configObjectType, []string{fxConfigName})
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(configKey, configJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	return nil
+}
+
+// GetFXConfig returns the FX configuration in use.
+func (s *SmartContract) GetFXConfig(ctx contractapi.TransactionContextInterface) (*FXConfig, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "GetFXConfig")
+	if err != nil {
+		return nil, err
+	}
+
+	return getFXConfig(ctx)
+}
+
+// SetFXRate stores the rate at which one unit of base converts into quote.
+// Only the rate setter orgs of the FX configuration may set rates.
+func (s *SmartContract) SetFXRate(ctx contractapi.TransactionContextInterface, base, quote, rate string) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "SetFXRate")
+	if err != nil {
+		return err
+	}
+
+	config, err := getFXConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !containsString(config.RateSetters, clientOrgID) {
+		return newError(CodeUnauthorized, "client from org %s is not authorized to set exchange rates", clientOrgID)
+	}
+
+	if _, err := CurrencyDigits(base); err != nil {
+		return newError(CodeInvalidArgument, "%v", err)
+	}
+	if _, err := CurrencyDigits(quote); err != nil {
+		return newError(CodeInvalidArgument, "%v", err)
+	}
+	if base == quote {
+		return newError(CodeInvalidArgument, "the base and quote currencies must differ")
+	}
+	if _, err := parseRate(rate); err != nil {
+		return err
+	}
+
+	timestamp, err := getTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	fxRate := FXRate{
+		Base:      base,
+		Quote:     quote,
+		Rate:      rate,
+		SetBy:     clientOrgID,
+		UpdatedAt: timestamp,
+	}
+	fxRateJSON, err := json.Marshal(fxRate)
+	if err != nil {
+		return err
+	}
+	rateKey, err := ctx.GetStub().CreateCompositeKey(fxRateObjectType, []string{base, quote})
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(rateKey, fxRateJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	return nil
+}
+
+// GetFXRates returns every exchange rate stored on the ledger.
+func (s *SmartContract) GetFXRates(ctx contractapi.TransactionContextInterface) ([]*FXRate, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "GetFXRates")
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(fxRateObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	var rates []*FXRate
+	for resultsIterator.HasNext() {
+		result, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var fxRate FXRate
+		err = json.Unmarshal(result.Value, &fxRate)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, &fxRate)
+	}
+
+	return rates, nil
+}
+
+// TransferWithConversion moves the given amount, expressed in minor units of the source account
+// currency, to an account holding a different currency. The amount credited is converted with the
+// rate stored on the ledger, rounded down to the minor unit of the destination currency, and the
+// transfer fails if the rate is older than the configured maximum rate age. Only the owner of the
+// source account can transfer. The transfer is recorded with the given memo, and the ID of its
+// record is the TxID of the returned conversion. The fee of the transfer, set by the fee schedule
+// for the source account and currency, is debited from the source account on top of the amount
+// and credited to the fee collector account.
+func (s *SmartContract) TransferWithConversion(ctx contractapi.TransactionContextInterface, fromId, toId string, amount int64, memo string) (*Conversion, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "TransferWithConversion")
+	if err != nil {
+		return nil, err
+	}
+
+	if amount <= 0 {
+		return nil, newError(CodeInvalidArgument, "amount must be positive")
+	}
+	err = verifyMemo(memo)
+	if err != nil {
+		return nil, err
+	}
+
+	fromAcc, err := getExistingAccount(ctx, fromId)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientIsAccountOwner(ctx, fromAcc)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyCanDebit(fromAcc)
+	if err != nil {
+		return nil, err
+	}
+
+	toAcc, err := getExistingAccount(ctx, toId)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyCanCredit(toAcc)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAcc.Currency == toAcc.Currency {
+		return nil, newError(CodeFailedPrecondition, "both accounts hold %s, use Transfer instead", fromAcc.Currency)
+	}
+
+	fee, collectorID, err := getTransferFee(ctx, fromAcc, Amount(amount))
+	if err != nil {
+		return nil, err
+	}
+	total, err := addAmounts(Amount(amount), fee)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "the amount plus its fee is out of range")
+	}
+	if fromAcc.Balance < total {
+		if fee > 0 {
+			return nil, newError(CodeInsufficientFunds, "the source account does not have enough balance for the amount and its fee of %s", fee.Format(fromAcc.Currency))
+		}
+		return nil, newError(CodeInsufficientFunds, "the source account does not have enough balance")
+	}
+
+	fxRate, err := getFXRate(ctx, fromAcc.Currency, toAcc.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := getFXConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp.Sub(fxRate.UpdatedAt) > time.Duration(config.MaxRateAge)*time.Second {
+		return nil, newError(CodeFailedPrecondition, "the %s/%s rate set at %s is older than %d seconds",
+			fxRate.Base,
+			fxRate.Quote,
+			fxRate.UpdatedAt.Format(time.RFC3339),
+			config.MaxRateAge,
+		)
+	}
+
+	converted, err := convertAmount(Amount(amount), fromAcc.Currency, toAcc.Currency, fxRate.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	toBalance, err := addAmounts(toAcc.Balance, converted)
+	if err != nil {
+		return nil, newError(CodeFailedPrecondition, "the destination account cannot hold the amount: %v", err)
+	}
+
+	// The fee collector holds the source currency, so it is never the destination account.
+	var collector *Account
+	if fee > 0 {
+		collector, err = getExistingAccount(ctx, collectorID)
+		if err != nil {
+			return nil, err
+		}
+		err = verifyCanCredit(collector)
+		if err != nil {
+			return nil, err
+		}
+		collector.Balance, err = addAmounts(collector.Balance, fee)
+		if err != nil {
+			return nil, newError(CodeFailedPrecondition, "the fee collector account cannot hold the fee: %v", err)
+		}
+	}
+
+	err = verifyDebitLimits(ctx, fromAcc, Amount(amount))
+	if err != nil {
+		return nil, err
+	}
+
+	fromAcc.Balance -= total
+	toAcc.Balance = toBalance
+
+	if err := putAccount(ctx, fromAcc); err != nil {
+		return nil, err
+	}
+	if err := putAccount(ctx, toAcc); err != nil {
+		return nil, err
+	}
+	if collector != nil {
+		if err := putAccount(ctx, collector); err != nil {
+			return nil, err
+		}
+	}
+
+	conversion := &Conversion{
+		TxID:          ctx.GetStub().GetTxID(),
+		FromID:        fromId,
+		FromAmount:    Amount(amount),
+		FromCurrency:  fromAcc.Currency,
+		ToID:          toId,
+		ToAmount:      converted,
+		ToCurrency:    toAcc.Currency,
+		Rate:          fxRate.Rate,
+		RateUpdatedAt: fxRate.UpdatedAt,
+		Timestamp:     timestamp,
+		Fee:           fee,
+		FeeCollector:  collectorID,
+	}
+	conversionJSON, err := json.Marshal(conversion)
+	if err != nil {
+		return nil, err
+	}
+	conversionKey, err := ctx.GetStub().CreateCompositeKey(conversionObjectType, []string{conversion.TxID})
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(conversionKey, conversionJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	transfer, err := newTransferRecord(ctx, fromAcc, toAcc, Amount(amount), converted, memo)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Rate = fxRate.Rate
+	transfer.Fee = fee
+	transfer.FeeCollector = collectorID
+	if err := putTransfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	err = emitEvent(ctx, EventFundsTransferred, &TransferEvent{
+		TransferID:   transfer.ID,
+		FromID:       fromId,
+		ToID:         toId,
+		Amount:       Amount(amount),
+		Currency:     fromAcc.Currency,
+		ToAmount:     converted,
+		ToCurrency:   toAcc.Currency,
+		FromBalance:  fromAcc.Balance,
+		ToBalance:    toAcc.Balance,
+		Fee:          fee,
+		FeeCollector: collectorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conversion, nil
+}
+
+// getFXConfig returns the FX configuration stored on the ledger, or the defaults if none was stored.
+func getFXConfig(ctx contractapi.TransactionContextInterface) (*FXConfig, error) {
+	configKey, err := ctx.GetStub().CreateCompositeKey(configObjectType, []string{fxConfigName})
+	if err != nil {
+		return nil, err
+	}
+	configJSON, err := ctx.GetStub().GetState(configKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if configJSON == nil {
+		return &FXConfig{RateSetters: defaultRateSetters, MaxRateAge: defaultMaxRateAge}, nil
+	}
+
+	var config FXConfig
+	err = json.Unmarshal(configJSON, &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// getFXRate returns the stored rate converting base into quote.
+func getFXRate(ctx contractapi.TransactionContextInterface, base, quote string) (*FXRate, error) {
+	rateKey, err := ctx.GetStub().CreateCompositeKey(fxRateObjectType, []string{base, quote})
+	if err != nil {
+		return nil, err
+	}
+	fxRateJSON, err := ctx.GetStub().GetState(rateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if fxRateJSON == nil {
+		return nil, newError(CodeNotFound, "there is no %s/%s exchange rate", base, quote)
+	}
+
+	var fxRate FXRate
+	err = json.Unmarshal(fxRateJSON, &fxRate)
+	if err != nil {
+		return nil, err
+	}
+	return &fxRate, nil
+}
+
+// parseRate parses a positive decimal exchange rate such as "0.9215".
+func parseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, newError(CodeInvalidArgument, "invalid exchange rate %q", rate)
+	}
+	return r, nil
+}
+
+// convertAmount converts an amount of one currency into another with the given rate,
+// rounding down to the minor unit of the target currency.
+func convertAmount(amount Amount, from, to, rate string) (Amount, error) {
+	r, err := parseRate(rate)
+	if err != nil {
+		return 0, err
+	}
+	fromDigits, err := CurrencyDigits(from)
+	if err != nil {
+		return 0, err
+	}
+	toDigits, err := CurrencyDigits(to)
+	if err != nil {
+		return 0, err
+	}
+
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(amount)), r)
+	converted.Mul(converted, new(big.Rat).SetInt64(pow10(toDigits)))
+	converted.Quo(converted, new(big.Rat).SetInt64(pow10(fromDigits)))
+
+	minor := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !minor.IsInt64() {
+		return 0, newError(CodeFailedPrecondition, "the converted amount is out of range")
+	}
+	if minor.Sign() == 0 {
+		return 0, newError(CodeFailedPrecondition, "the converted amount is smaller than the minor unit of the destination currency")
+	}
+	return Amount(minor.Int64()), nil
+}
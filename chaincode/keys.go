@@ -0,0 +1,171 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Accounts are stored under the composite key account/[id], and indexed by bank under
+// account~bank/[bank, id]. Accounts written before keys were namespaced are stored under their
+// plain ID: they are read from there and moved to their composite key the next time they are
+// written, or by MigrateAccounts.
+const (
+	accountObjectType  = "account"
+	accountByBankIndex = "account~bank"
+)
+
+// indexValue is the value of the index entries, which only need their key.
+var indexValue = []byte{0x00}
+
+// accountKey returns the key of the account with the given ID.
+func accountKey(ctx contractapi.TransactionContextInterface, accountID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(accountObjectType, []string{accountID})
+	if err != nil {
+		return "", newError(CodeInvalidArgument, "invalid account ID %q: %v", accountID, err)
+	}
+
+	return key, nil
+}
+
+// accountBankIndexKey returns the key of the bank index entry of an account.
+func accountBankIndexKey(ctx contractapi.TransactionContextInterface, bank, accountID string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(accountByBankIndex, []string{bank, accountID})
+	if err != nil {
+		return "", newError(CodeInvalidArgument, "invalid bank %q: %v", bank, err)
+	}
+
+	return key, nil
+}
+
+// getAccount reads the account with the given ID from the world state, falling back to its legacy
+// key. It returns nil when the account does not exist.
+func getAccount(ctx contractapi.TransactionContextInterface, accountID string) (*Account, error) {
+	key, err := accountKey(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	accountJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	legacyKey := false
+	if accountJSON == nil {
+		accountJSON, err = ctx.GetStub().GetState(accountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from world state: %v", err)
+		}
+		if accountJSON == nil {
+			return nil, nil
+		}
+		legacyKey = true
+	}
+
+	account, err := unmarshalAccount(accountJSON)
+	if err != nil {
+		return nil, err
+	}
+	account.legacyKey = legacyKey
+	return account, nil
+}
+
+// getExistingAccount reads the account with the given ID from the world state, failing with
+// CodeNotFound when it does not exist.
+func getExistingAccount(ctx contractapi.TransactionContextInterface, accountID string) (*Account, error) {
+	account, err := getAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, newError(CodeNotFound, "the account %s does not exist", accountID)
+	}
+	return account, nil
+}
+
+// putAccount writes the account and its bank index entry to the world state. An account read from
+// its legacy key is moved to its composite key, along with its endorsement policy.
+func putAccount(ctx contractapi.TransactionContextInterface, account *Account) error {
+	key, err := accountKey(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+	indexKey, err := accountBankIndexKey(ctx, account.Bank, account.ID)
+	if err != nil {
+		return err
+	}
+
+	accountJSON, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, accountJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	err = ctx.GetStub().PutState(indexKey, indexValue)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	if account.legacyKey {
+		policy, err := ctx.GetStub().GetStateValidationParameter(account.ID)
+		if err != nil {
+			return fmt.Errorf("failed to read validation parameter of account %s: %v", account.ID, err)
+		}
+		if policy != nil {
+			err = ctx.GetStub().SetStateValidationParameter(key, policy)
+			if err != nil {
+				return fmt.Errorf("failed to set validation parameter on account %s: %v", account.ID, err)
+			}
+		}
+		err = ctx.GetStub().DelState(account.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete legacy key of account %s: %v", account.ID, err)
+		}
+		account.legacyKey = false
+	}
+
+	return nil
+}
+
+// delAccount deletes the account and its bank index entry from the world state.
+func delAccount(ctx contractapi.TransactionContextInterface, account *Account) error {
+	key := account.ID
+	if !account.legacyKey {
+		var err error
+		key, err = accountKey(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+	}
+	indexKey, err := accountBankIndexKey(ctx, account.Bank, account.ID)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return fmt.Errorf("failed to delete from world state: %v", err)
+	}
+	// Legacy accounts may have no index entry, deleting it anyway is harmless.
+	err = ctx.GetStub().DelState(indexKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete from world state: %v", err)
+	}
+
+	return nil
+}
+
+// accountIDFromKey returns the account ID of an account key or bank index entry key.
+func accountIDFromKey(ctx contractapi.TransactionContextInterface, key string) (string, error) {
+	_, attributes, err := ctx.GetStub().SplitCompositeKey(key)
+	if err != nil {
+		return "", err
+	}
+	if len(attributes) == 0 {
+		return "", fmt.Errorf("the key %q has no account ID", key)
+	}
+
+	return attributes[len(attributes)-1], nil
+}
@@ -0,0 +1,204 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Statuses of the lifecycle of an account.
+const (
+	// StatusPending is an account that is not open yet: it can neither send nor receive money.
+	StatusPending = "pending"
+	// StatusActive is an open account.
+	StatusActive = "active"
+	// StatusDormant is an account that can receive money but not send it until it is active again.
+	StatusDormant = "dormant"
+	// StatusClosed is a closed account. It is kept, with a zero balance, as a tombstone so that its
+	// ID is not reused, and it can neither send nor receive money.
+	StatusClosed = "closed"
+)
+
+// closureMemo is the memo of the transfer sweeping the balance of an account being closed.
+const closureMemo = "account closure"
+
+// SetAccountStatus moves an account between the pending, active and dormant statuses. Closed
+// accounts cannot change status, and accounts are only closed by CloseAccount.
+func (s *SmartContract) SetAccountStatus(ctx contractapi.TransactionContextInterface, accountID, status string) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "SetAccountStatus")
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case StatusPending, StatusActive, StatusDormant:
+	case StatusClosed:
+		return newError(CodeInvalidArgument, "accounts are closed with CloseAccount")
+	default:
+		return newError(CodeInvalidArgument, "invalid status %q, it must be %s, %s or %s", status, StatusPending, StatusActive, StatusDormant)
+	}
+
+	account, err := getExistingAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	err = verifyNotClosed(account)
+	if err != nil {
+		return err
+	}
+	if account.Status == status {
+		return newError(CodeFailedPrecondition, "the account %s is already %s", accountID, status)
+	}
+
+	event := &StatusEvent{AccountID: accountID, From: account.Status, To: status}
+	account.Status = status
+	err = putAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, EventAccountStatusChanged, event)
+}
+
+// CloseAccount closes an account, leaving it as a tombstone. An account holding funds can only be
+// closed by sweeping its whole balance to the sweepTo account, which must hold the same currency;
+// with an empty sweepTo the account must have a zero balance. The sweep is a debit, so pending and
+// dormant accounts can only be closed once empty, and it must be within the limits of the account.
+// It is not charged a fee, since it must leave the account empty and only admins close accounts.
+// Admins can close the accounts of any owner, but neither frozen accounts nor those with locked
+// escrows can be closed.
+func (s *SmartContract) CloseAccount(ctx contractapi.TransactionContextInterface, accountID, sweepTo string) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "CloseAccount")
+	if err != nil {
+		return err
+	}
+
+	return closeAccount(ctx, accountID, sweepTo)
+}
+
+// closeAccount closes an account, sweeping its balance to the sweepTo account when it is not empty.
+func closeAccount(ctx contractapi.TransactionContextInterface, accountID, sweepTo string) error {
+	account, err := getExistingAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	// Only admins close accounts, and they can close those of any owner.
+	err = verifyNotClosed(account)
+	if err != nil {
+		return err
+	}
+	if account.Freeze != nil {
+		return newError(CodeAccountFrozen, "the account %s is frozen and cannot be closed", accountID)
+	}
+	err = verifyNoLockedEscrows(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	event := &ClosureEvent{AccountID: account.ID, Bank: account.Bank, Currency: account.Currency}
+	if account.Balance != 0 {
+		if sweepTo == "" {
+			return newError(CodeFailedPrecondition, "the account %s holds %s, sweep it to another account to close it", accountID, account.Balance.Format(account.Currency))
+		}
+		if sweepTo == accountID {
+			return newError(CodeInvalidArgument, "an account cannot be swept to itself")
+		}
+		err = verifyCanDebit(account)
+		if err != nil {
+			return err
+		}
+		err = verifyDebitLimits(ctx, account, account.Balance)
+		if err != nil {
+			return err
+		}
+		toAcc, err := getExistingAccount(ctx, sweepTo)
+		if err != nil {
+			return err
+		}
+		err = verifyCanCredit(toAcc)
+		if err != nil {
+			return err
+		}
+		if toAcc.Currency != account.Currency {
+			return newError(CodeFailedPrecondition, "cannot sweep to an account in a different currency (%s and %s)", account.Currency, toAcc.Currency)
+		}
+		toBalance, err := addAmounts(toAcc.Balance, account.Balance)
+		if err != nil {
+			return newError(CodeFailedPrecondition, "the destination account cannot hold the amount: %v", err)
+		}
+
+		transfer, err := newTransferRecord(ctx, account, toAcc, account.Balance, account.Balance, closureMemo)
+		if err != nil {
+			return err
+		}
+		toAcc.Balance = toBalance
+		if err := putAccount(ctx, toAcc); err != nil {
+			return err
+		}
+		if err := putTransfer(ctx, transfer); err != nil {
+			return err
+		}
+		event.SweptTo = toAcc.ID
+		event.SweptAmount = account.Balance
+		event.TransferID = transfer.ID
+	}
+
+	account.Balance = 0
+	account.Status = StatusClosed
+	err = putAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return emitEvent(ctx, EventAccountClosed, event)
+}
+
+// verifyNotClosed checks that the account is not closed.
+func verifyNotClosed(account *Account) error {
+	if account.Status == StatusClosed {
+		return newError(CodeFailedPrecondition, "the account %s is closed", account.ID)
+	}
+	return nil
+}
+
+// verifyCanDebit checks that the status and the freeze of the account allow debiting it. Every
+// function moving money out of an account must call it.
+func verifyCanDebit(account *Account) error {
+	if account.Status != StatusActive {
+		return newError(CodeFailedPrecondition, "the account %s is %s and cannot send money", account.ID, account.Status)
+	}
+	if account.Freeze != nil && account.Freeze.Mode != FreezeCredit {
+		return newError(CodeAccountFrozen, "the account %s is frozen for debits (%s)", account.ID, account.Freeze.Reason)
+	}
+	return nil
+}
+
+// verifyCanCredit checks that the status and the freeze of the account allow crediting it. Every
+// function moving money into an account must call it.
+func verifyCanCredit(account *Account) error {
+	if account.Status != StatusActive && account.Status != StatusDormant {
+		return newError(CodeFailedPrecondition, "the account %s is %s and cannot receive money", account.ID, account.Status)
+	}
+	if account.Freeze != nil && account.Freeze.Mode != FreezeDebit {
+		return newError(CodeAccountFrozen, "the account %s is frozen for credits (%s)", account.ID, account.Freeze.Reason)
+	}
+	return nil
+}
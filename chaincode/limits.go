@@ -0,0 +1,247 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// The limits of an account are stored under limits/[account ID], and what it has debited under
+// limitusage/[account ID], which every debit subject to the limits rewrites.
+const (
+	limitsObjectType     = "limits"
+	limitUsageObjectType = "limitusage"
+	// limitDayLayout and limitMonthLayout format the UTC day and month the usage counters are for.
+	limitDayLayout   = "2006-01-02"
+	limitMonthLayout = "2006-01"
+)
+
+// AccountLimits are the velocity limits of an account, in minor units of its currency: the maximum
+// amount of a single transfer, and the maximum total debited in a day and in a month, both UTC
+// calendar periods of the transaction timestamp. A zero limit is no limit.
+type AccountLimits struct {
+	AccountID   string `json:"AccountID"`
+	MaxTransfer Amount `json:"MaxTransfer"`
+	Daily       Amount `json:"Daily"`
+	Monthly     Amount `json:"Monthly"`
+	// Usage is what the account has debited in the current day and month. It is not stored with
+	// the limits, GetAccountLimits fills it in.
+	Usage *LimitUsage `json:"Usage,omitempty" metadata:"Usage,optional"`
+}
+
+// LimitUsage holds the totals debited from an account in a day and in a month.
+type LimitUsage struct {
+	Day          string `json:"Day"`
+	DailyTotal   Amount `json:"DailyTotal"`
+	Month        string `json:"Month"`
+	MonthlyTotal Amount `json:"MonthlyTotal"`
+}
+
+// debitLimits are the limits and usage of an account being debited.
+type debitLimits struct {
+	account *Account
+	limits  *AccountLimits
+	usage   *LimitUsage
+}
+
+// SetAccountLimits sets the limits of an account, in minor units of its currency. A zero limit
+// removes that limit. What the account debited before the limits were set counts towards them.
+func (s *SmartContract) SetAccountLimits(ctx contractapi.TransactionContextInterface, accountID string, maxTransfer, daily, monthly int64) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "SetAccountLimits")
+	if err != nil {
+		return err
+	}
+
+	if maxTransfer < 0 || daily < 0 || monthly < 0 {
+		return newError(CodeInvalidArgument, "limits must not be negative")
+	}
+	if daily > 0 && monthly > 0 && daily > monthly {
+		return newError(CodeInvalidArgument, "the daily limit must not exceed the monthly limit")
+	}
+
+	account, err := getExistingAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	err = verifyNotClosed(account)
+	if err != nil {
+		return err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(limitsObjectType, []string{accountID})
+	if err != nil {
+		return newError(CodeInvalidArgument, "invalid account ID %q: %v", accountID, err)
+	}
+	if maxTransfer == 0 && daily == 0 && monthly == 0 {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete from world state: %v", err)
+		}
+		return nil
+	}
+
+	limitsJSON, err := json.Marshal(AccountLimits{
+		AccountID:   accountID,
+		MaxTransfer: Amount(maxTransfer),
+		Daily:       Amount(daily),
+		Monthly:     Amount(monthly),
+	})
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, limitsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	return nil
+}
+
+// GetAccountLimits returns the limits of an account, all zero when it has none, with what it has
+// debited in the current day and month.
+func (s *SmartContract) GetAccountLimits(ctx contractapi.TransactionContextInterface, accountID string) (*AccountLimits, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "GetAccountLimits")
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := getExistingAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	debit, err := getDebitLimits(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := *debit.limits
+	limits.Usage = debit.usage
+	return &limits, nil
+}
+
+// getDebitLimits reads the limits of an account and its usage in the day and month of the current
+// transaction.
+func getDebitLimits(ctx contractapi.TransactionContextInterface, account *Account) (*debitLimits, error) {
+	limits := &AccountLimits{AccountID: account.ID}
+	err := getLimitsObject(ctx, limitsObjectType, account.ID, limits)
+	if err != nil {
+		return nil, err
+	}
+	usage := &LimitUsage{}
+	err = getLimitsObject(ctx, limitUsageObjectType, account.ID, usage)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	day, month := timestamp.UTC().Format(limitDayLayout), timestamp.UTC().Format(limitMonthLayout)
+	if usage.Day != day {
+		usage.Day, usage.DailyTotal = day, 0
+	}
+	if usage.Month != month {
+		usage.Month, usage.MonthlyTotal = month, 0
+	}
+
+	return &debitLimits{account: account, limits: limits, usage: usage}, nil
+}
+
+// getLimitsObject reads the limits or usage of an account into value, leaving it as it is when the
+// account has none.
+func getLimitsObject(ctx contractapi.TransactionContextInterface, objectType, accountID string, value interface{}) error {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, []string{accountID})
+	if err != nil {
+		return newError(CodeInvalidArgument, "invalid account ID %q: %v", accountID, err)
+	}
+	valueJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if valueJSON == nil {
+		return nil
+	}
+	return json.Unmarshal(valueJSON, value)
+}
+
+// add checks that debiting the amount keeps the account within its limits, and adds it to the
+// usage. The usage is left as it was when a limit would be exceeded.
+func (debit *debitLimits) add(amount Amount) error {
+	limits, currency := debit.limits, debit.account.Currency
+	if limits.MaxTransfer > 0 && amount > limits.MaxTransfer {
+		return newError(CodeLimitExceeded, "the transfer of %s exceeds the limit of %s per transfer of the account %s",
+			amount.Format(currency), limits.MaxTransfer.Format(currency), debit.account.ID)
+	}
+	dailyTotal, err := addAmounts(debit.usage.DailyTotal, amount)
+	if err != nil {
+		return newError(CodeLimitExceeded, "the daily total of the account %s is out of range", debit.account.ID)
+	}
+	monthlyTotal, err := addAmounts(debit.usage.MonthlyTotal, amount)
+	if err != nil {
+		return newError(CodeLimitExceeded, "the monthly total of the account %s is out of range", debit.account.ID)
+	}
+	if limits.Daily > 0 && dailyTotal > limits.Daily {
+		return newError(CodeLimitExceeded, "the transfer of %s exceeds the daily limit of %s of the account %s, of which %s is used",
+			amount.Format(currency), limits.Daily.Format(currency), debit.account.ID, debit.usage.DailyTotal.Format(currency))
+	}
+	if limits.Monthly > 0 && monthlyTotal > limits.Monthly {
+		return newError(CodeLimitExceeded, "the transfer of %s exceeds the monthly limit of %s of the account %s, of which %s is used",
+			amount.Format(currency), limits.Monthly.Format(currency), debit.account.ID, debit.usage.MonthlyTotal.Format(currency))
+	}
+
+	debit.usage.DailyTotal, debit.usage.MonthlyTotal = dailyTotal, monthlyTotal
+	return nil
+}
+
+// put writes the usage of the account to the world state.
+func (debit *debitLimits) put(ctx contractapi.TransactionContextInterface) error {
+	key, err := ctx.GetStub().CreateCompositeKey(limitUsageObjectType, []string{debit.account.ID})
+	if err != nil {
+		return newError(CodeInvalidArgument, "invalid account ID %q: %v", debit.account.ID, err)
+	}
+	usageJSON, err := json.Marshal(debit.usage)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, usageJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+	return nil
+}
+
+// verifyDebitLimits checks that debiting the amount from the account keeps it within its limits, and
+// records the debit in its usage. Every transfer out of an account must call it.
+func verifyDebitLimits(ctx contractapi.TransactionContextInterface, account *Account, amount Amount) error {
+	debit, err := getDebitLimits(ctx, account)
+	if err != nil {
+		return err
+	}
+	err = debit.add(amount)
+	if err != nil {
+		return err
+	}
+	return debit.put(ctx)
+}
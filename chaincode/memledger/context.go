@@ -0,0 +1,19 @@
+package memledger
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// NewTransactionContext returns the transaction context given by contractapi to the contract
+// functions running in the transaction of the stub.
+func NewTransactionContext(stub *Stub) (*contractapi.TransactionContext, error) {
+	clientIdentity, err := cid.New(stub)
+	if err != nil {
+		return nil, err
+	}
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(stub)
+	ctx.SetClientIdentity(clientIdentity)
+	return ctx, nil
+}
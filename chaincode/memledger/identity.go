@@ -0,0 +1,71 @@
+package memledger
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/attrmgr"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// Identity is the X.509 identity of a client of the ledger.
+type Identity struct {
+	MSPID string
+	// Certificate is the PEM encoded certificate of the client.
+	Certificate []byte
+}
+
+// NewIdentity returns an identity of the given MSP with a new self-signed certificate. The
+// attributes are embedded in the certificate as the Fabric CA does, so that they can be read with
+// the client identity's GetAttributeValue.
+func NewIdentity(mspID, commonName string, organizationalUnits []string, attributes map[string]string) (*Identity, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:         commonName,
+			OrganizationalUnit: organizationalUnits,
+		},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	if len(attributes) > 0 {
+		value, err := json.Marshal(&attrmgr.Attributes{Attrs: attributes})
+		if err != nil {
+			return nil, err
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{Id: attrmgr.AttrOID, Value: value})
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{
+		MSPID:       mspID,
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}, nil
+}
+
+// Creator returns the serialized identity sent by the client as the creator of its proposals.
+func (identity *Identity) Creator() ([]byte, error) {
+	return proto.Marshal(&msp.SerializedIdentity{Mspid: identity.MSPID, IdBytes: identity.Certificate})
+}
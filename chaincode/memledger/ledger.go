@@ -0,0 +1,279 @@
+// Package memledger is an in-memory ledger that runs the HyperPay contract without a Fabric network.
+// It implements shim.ChaincodeStubInterface with the semantics of a peer: reads see the committed
+// world state only, writes are applied when the transaction commits, history is kept per key,
+// and transactions whose reads were changed by a concurrent commit are rejected.
+package memledger
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Event is a chaincode event emitted by a committed transaction.
+type Event struct {
+	TxID string
+	// BlockNumber is the position of the transaction among the committed ones, starting at 1.
+	BlockNumber uint64
+	Name        string
+	Payload     []byte
+}
+
+// entry is the committed value of a key.
+type entry struct {
+	value []byte
+	// version is the number of the transaction that last wrote the key.
+	version uint64
+}
+
+// Ledger is the world state, history and events of a channel. It is safe for concurrent use.
+type Ledger struct {
+	channelID string
+	peerMSPID string
+
+	mu         sync.RWMutex
+	now        func() time.Time
+	state      map[string]*entry
+	validation map[string][]byte
+	history    map[string][]*queryresult.KeyModification
+	events     []Event
+	// txCount numbers the proposals and the commits, giving the versions of the keys.
+	txCount uint64
+	// blockCount is the number of committed transactions.
+	blockCount uint64
+}
+
+// NewLedger returns an empty ledger for the given channel, endorsed by a peer of the given MSP.
+func NewLedger(channelID, peerMSPID string) *Ledger {
+	return &Ledger{
+		channelID:  channelID,
+		peerMSPID:  peerMSPID,
+		now:        time.Now,
+		state:      map[string]*entry{},
+		validation: map[string][]byte{},
+		history:    map[string][]*queryresult.KeyModification{},
+	}
+}
+
+// SetClock sets the function giving the timestamp of new transactions. It defaults to time.Now.
+func (ledger *Ledger) SetClock(now func() time.Time) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	ledger.now = now
+}
+
+// PeerMSPID returns the MSP ID of the peer endorsing the transactions.
+func (ledger *Ledger) PeerMSPID() string {
+	return ledger.peerMSPID
+}
+
+// NewStub starts a transaction invoking the given function and arguments on behalf of identity.
+// Its writes are only visible after the stub is committed with Commit.
+func (ledger *Ledger) NewStub(identity *Identity, args ...string) (*Stub, error) {
+	creator, err := identity.Creator()
+	if err != nil {
+		return nil, err
+	}
+
+	ledger.mu.Lock()
+	ledger.txCount++
+	nonce := ledger.txCount
+	timestamp := ledger.now()
+	ledger.mu.Unlock()
+
+	byteArgs := make([][]byte, len(args))
+	for i, arg := range args {
+		byteArgs[i] = []byte(arg)
+	}
+	return newStub(ledger, newTxID(nonce, creator), creator, timestamp, byteArgs), nil
+}
+
+// Commit validates the transaction of the stub and applies its writes, validation parameters and
+// event. It fails, without applying anything, when a key read by the transaction was written by
+// another transaction committed after the stub was created.
+func (ledger *Ledger) Commit(stub *Stub) error {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+
+	if stub.committed {
+		return fmt.Errorf("transaction %s was already committed", stub.txID)
+	}
+	for key, version := range stub.reads {
+		var current uint64
+		if e, ok := ledger.state[key]; ok {
+			current = e.version
+		}
+		if current != version {
+			return fmt.Errorf("transaction %s failed validation: MVCC_READ_CONFLICT on key %q", stub.txID, key)
+		}
+	}
+
+	ledger.txCount++
+	ledger.blockCount++
+	version := ledger.txCount
+	timestamp := stub.timestamp
+	for _, key := range stub.writeOrder {
+		w := stub.writes[key]
+		modification := &queryresult.KeyModification{
+			TxId:      stub.txID,
+			Value:     w.value,
+			Timestamp: timestamp,
+			IsDelete:  w.delete,
+		}
+		ledger.history[key] = append(ledger.history[key], modification)
+		if w.delete {
+			delete(ledger.state, key)
+			delete(ledger.validation, key)
+			continue
+		}
+		ledger.state[key] = &entry{value: w.value, version: version}
+	}
+	for key, ep := range stub.validation {
+		ledger.validation[key] = ep
+	}
+	if stub.event != nil {
+		ledger.events = append(ledger.events, Event{
+			TxID:        stub.txID,
+			BlockNumber: ledger.blockCount,
+			Name:        stub.event.EventName,
+			Payload:     stub.event.Payload,
+		})
+	}
+	stub.committed = true
+
+	return nil
+}
+
+// Submit invokes the chaincode on behalf of identity and commits the transaction when the chaincode
+// succeeds, as a client submitting a transaction would. It returns the transaction ID and the
+// chaincode response, whose status is an error when the transaction fails to commit.
+func (ledger *Ledger) Submit(cc shim.Chaincode, identity *Identity, args ...string) (string, peer.Response) {
+	stub, err := ledger.NewStub(identity, args...)
+	if err != nil {
+		return "", shim.Error(err.Error())
+	}
+
+	response := cc.Invoke(stub)
+	if response.Status >= shim.ERRORTHRESHOLD {
+		return stub.txID, response
+	}
+	if err := ledger.Commit(stub); err != nil {
+		return stub.txID, shim.Error(err.Error())
+	}
+	return stub.txID, response
+}
+
+// Evaluate invokes the chaincode on behalf of identity without committing the transaction, as a
+// client evaluating a transaction would.
+func (ledger *Ledger) Evaluate(cc shim.Chaincode, identity *Identity, args ...string) peer.Response {
+	stub, err := ledger.NewStub(identity, args...)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return cc.Invoke(stub)
+}
+
+// GetState returns the committed value of the key, or nil when the key does not exist.
+func (ledger *Ledger) GetState(key string) []byte {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+	if e, ok := ledger.state[key]; ok {
+		return e.value
+	}
+	return nil
+}
+
+// GetStateValidationParameter returns the committed validation parameter of the key.
+func (ledger *Ledger) GetStateValidationParameter(key string) []byte {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+	return ledger.validation[key]
+}
+
+// Keys returns the keys of the world state in lexical order, composite keys first.
+func (ledger *Ledger) Keys() []string {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+	return ledger.sortedKeys()
+}
+
+// Events returns the events emitted by the committed transactions, oldest first.
+func (ledger *Ledger) Events() []Event {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+	events := make([]Event, len(ledger.events))
+	copy(events, ledger.events)
+	return events
+}
+
+// rangeQuery returns the committed entries with startKey <= key < endKey, where an empty endKey
+// has no upper bound. When limit is positive at most limit entries are returned, along with the key
+// following the last one, or an empty string when there are no more entries.
+func (ledger *Ledger) rangeQuery(startKey, endKey string, limit int) ([]*queryresult.KV, string, map[string]uint64) {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+
+	var results []*queryresult.KV
+	versions := map[string]uint64{}
+	for _, key := range ledger.sortedKeys() {
+		if key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		if limit > 0 && len(results) == limit {
+			return results, key, versions
+		}
+		e := ledger.state[key]
+		results = append(results, &queryresult.KV{Namespace: "", Key: key, Value: e.value})
+		versions[key] = e.version
+	}
+	return results, "", versions
+}
+
+// keyHistory returns the modifications of the key, newest first as a Fabric 2 peer does.
+func (ledger *Ledger) keyHistory(key string) []*queryresult.KeyModification {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+
+	modifications := ledger.history[key]
+	history := make([]*queryresult.KeyModification, len(modifications))
+	for i, modification := range modifications {
+		history[len(modifications)-1-i] = modification
+	}
+	return history
+}
+
+// committedState returns the committed value and version of the key.
+func (ledger *Ledger) committedState(key string) ([]byte, uint64) {
+	ledger.mu.RLock()
+	defer ledger.mu.RUnlock()
+	if e, ok := ledger.state[key]; ok {
+		return e.value, e.version
+	}
+	return nil, 0
+}
+
+func (ledger *Ledger) sortedKeys() []string {
+	keys := make([]string, 0, len(ledger.state))
+	for key := range ledger.state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newTxID returns the transaction ID of a proposal, which a client computes as the hash of its
+// nonce and creator.
+func newTxID(nonce uint64, creator []byte) string {
+	nonceBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(nonceBytes, nonce)
+	hash := sha256.Sum256(append(nonceBytes, creator...))
+	return hex.EncodeToString(hash[:])
+}
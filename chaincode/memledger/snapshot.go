@@ -0,0 +1,106 @@
+package memledger
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// snapshot is the JSON representation of a ledger written by Save.
+type snapshot struct {
+	ChannelID  string                            `json:"channelId"`
+	TxCount    uint64                            `json:"txCount"`
+	BlockCount uint64                            `json:"blockCount"`
+	State      map[string]snapshotEntry          `json:"state"`
+	Validation map[string][]byte                 `json:"validation,omitempty"`
+	History    map[string][]snapshotModification `json:"history"`
+	Events     []Event                           `json:"events,omitempty"`
+}
+
+type snapshotEntry struct {
+	Value   []byte `json:"value"`
+	Version uint64 `json:"version"`
+}
+
+type snapshotModification struct {
+	TxID      string    `json:"txId"`
+	Value     []byte    `json:"value,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	IsDelete  bool      `json:"isDelete,omitempty"`
+}
+
+// Save writes the world state, history and events of the ledger as JSON.
+func (ledger *Ledger) Save(w io.Writer) error {
+	ledger.mu.RLock()
+	s := snapshot{
+		ChannelID:  ledger.channelID,
+		TxCount:    ledger.txCount,
+		BlockCount: ledger.blockCount,
+		State:      make(map[string]snapshotEntry, len(ledger.state)),
+		Validation: ledger.validation,
+		History:    make(map[string][]snapshotModification, len(ledger.history)),
+		Events:     ledger.events,
+	}
+	for key, e := range ledger.state {
+		s.State[key] = snapshotEntry{Value: e.value, Version: e.version}
+	}
+	for key, modifications := range ledger.history {
+		history := make([]snapshotModification, len(modifications))
+		for i, modification := range modifications {
+			timestamp, err := ptypes.Timestamp(modification.Timestamp)
+			if err != nil {
+				ledger.mu.RUnlock()
+				return err
+			}
+			history[i] = snapshotModification{
+				TxID:      modification.TxId,
+				Value:     modification.Value,
+				Timestamp: timestamp,
+				IsDelete:  modification.IsDelete,
+			}
+		}
+		s.History[key] = history
+	}
+	ledger.mu.RUnlock()
+
+	return json.NewEncoder(w).Encode(&s)
+}
+
+// Load reads a ledger written by Save, endorsed by a peer of the given MSP.
+func Load(r io.Reader, peerMSPID string) (*Ledger, error) {
+	var s snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+
+	ledger := NewLedger(s.ChannelID, peerMSPID)
+	ledger.txCount = s.TxCount
+	ledger.blockCount = s.BlockCount
+	ledger.events = s.Events
+	for key, e := range s.State {
+		ledger.state[key] = &entry{value: e.Value, version: e.Version}
+	}
+	for key, ep := range s.Validation {
+		ledger.validation[key] = ep
+	}
+	for key, history := range s.History {
+		modifications := make([]*queryresult.KeyModification, len(history))
+		for i, modification := range history {
+			timestamp, err := ptypes.TimestampProto(modification.Timestamp)
+			if err != nil {
+				return nil, err
+			}
+			modifications[i] = &queryresult.KeyModification{
+				TxId:      modification.TxID,
+				Value:     modification.Value,
+				Timestamp: timestamp,
+				IsDelete:  modification.IsDelete,
+			}
+		}
+		ledger.history[key] = modifications
+	}
+	return ledger, nil
+}
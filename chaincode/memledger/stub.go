@@ -0,0 +1,453 @@
+package memledger
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+const (
+	minUnicodeRuneValue   = 0            // U+0000
+	maxUnicodeRuneValue   = utf8.MaxRune // U+10FFFF
+	compositeKeyNamespace = "\x00"
+	emptyKeySubstitute    = "\x01"
+)
+
+// errPrivateData is returned by the private data functions, which the ledger does not support.
+var errPrivateData = errors.New("private data is not supported by the in-memory ledger")
+
+// write is a pending write of a transaction.
+type write struct {
+	value  []byte
+	delete bool
+}
+
+// Stub is the shim.ChaincodeStubInterface of a transaction running on a Ledger.
+type Stub struct {
+	ledger    *Ledger
+	txID      string
+	creator   []byte
+	timestamp *timestamp.Timestamp
+	args      [][]byte
+	transient map[string][]byte
+
+	// reads holds the version of the keys read by the transaction, 0 for missing keys.
+	reads      map[string]uint64
+	writes     map[string]*write
+	writeOrder []string
+	validation map[string][]byte
+	event      *peer.ChaincodeEvent
+	committed  bool
+}
+
+var _ shim.ChaincodeStubInterface = (*Stub)(nil)
+
+func newStub(ledger *Ledger, txID string, creator []byte, now time.Time, args [][]byte) *Stub {
+	txTimestamp, err := ptypes.TimestampProto(now)
+	if err != nil {
+		txTimestamp = ptypes.TimestampNow()
+	}
+	return &Stub{
+		ledger:     ledger,
+		txID:       txID,
+		creator:    creator,
+		timestamp:  txTimestamp,
+		args:       args,
+		transient:  map[string][]byte{},
+		reads:      map[string]uint64{},
+		writes:     map[string]*write{},
+		validation: map[string][]byte{},
+	}
+}
+
+// GetPeerMSPID returns the MSP ID of the peer running the transaction, which a chaincode deployed
+// on a peer reads with shim.GetMSPID.
+func (stub *Stub) GetPeerMSPID() (string, error) {
+	return stub.ledger.peerMSPID, nil
+}
+
+// SetTransient sets the transient data of the transaction.
+func (stub *Stub) SetTransient(transient map[string][]byte) {
+	stub.transient = transient
+}
+
+// Event returns the event set by the transaction, or nil.
+func (stub *Stub) Event() *peer.ChaincodeEvent {
+	return stub.event
+}
+
+// GetArgs returns the arguments of the transaction.
+func (stub *Stub) GetArgs() [][]byte {
+	return stub.args
+}
+
+// GetStringArgs returns the arguments of the transaction as strings.
+func (stub *Stub) GetStringArgs() []string {
+	args := make([]string, len(stub.args))
+	for i, arg := range stub.args {
+		args[i] = string(arg)
+	}
+	return args
+}
+
+// GetFunctionAndParameters returns the first argument as the function and the rest as parameters.
+func (stub *Stub) GetFunctionAndParameters() (string, []string) {
+	args := stub.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+// GetArgsSlice returns the arguments of the transaction concatenated.
+func (stub *Stub) GetArgsSlice() ([]byte, error) {
+	var slice []byte
+	for _, arg := range stub.args {
+		slice = append(slice, arg...)
+	}
+	return slice, nil
+}
+
+// GetTxID returns the ID of the transaction.
+func (stub *Stub) GetTxID() string {
+	return stub.txID
+}
+
+// GetChannelID returns the channel of the ledger.
+func (stub *Stub) GetChannelID() string {
+	return stub.ledger.channelID
+}
+
+// InvokeChaincode always fails: the ledger runs a single chaincode.
+func (stub *Stub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) peer.Response {
+	return shim.Error(fmt.Sprintf("cannot invoke chaincode %s: chaincode to chaincode calls are not supported by the in-memory ledger", chaincodeName))
+}
+
+// GetState returns the committed value of the key. Writes of the transaction are not visible,
+// as on a peer.
+func (stub *Stub) GetState(key string) ([]byte, error) {
+	if key == "" {
+		return nil, errors.New("key must not be an empty string")
+	}
+	value, version := stub.ledger.committedState(key)
+	stub.reads[key] = version
+	return value, nil
+}
+
+// PutState writes the value of the key when the transaction commits.
+func (stub *Stub) PutState(key string, value []byte) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	if !utf8.ValidString(key) {
+		return fmt.Errorf("key %q is not a valid UTF-8 string", key)
+	}
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+	stub.addWrite(key, &write{value: valueCopy})
+	return nil
+}
+
+// DelState deletes the key when the transaction commits.
+func (stub *Stub) DelState(key string) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	stub.addWrite(key, &write{delete: true})
+	return nil
+}
+
+func (stub *Stub) addWrite(key string, w *write) {
+	if _, ok := stub.writes[key]; !ok {
+		stub.writeOrder = append(stub.writeOrder, key)
+	}
+	stub.writes[key] = w
+}
+
+// SetStateValidationParameter sets the key-level endorsement policy of the key.
+func (stub *Stub) SetStateValidationParameter(key string, ep []byte) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	stub.validation[key] = ep
+	return nil
+}
+
+// GetStateValidationParameter returns the committed key-level endorsement policy of the key.
+func (stub *Stub) GetStateValidationParameter(key string) ([]byte, error) {
+	return stub.ledger.GetStateValidationParameter(key), nil
+}
+
+// GetStateByRange returns the committed keys with startKey <= key < endKey. Composite keys are
+// excluded, and an empty endKey has no upper bound.
+func (stub *Stub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	results, _, versions := stub.ledger.rangeQuery(startKey, endKey, 0)
+	stub.addReads(versions)
+	return &stateIterator{results: results}, nil
+}
+
+// GetStateByRangeWithPagination returns a page of GetStateByRange. The bookmark of the response
+// metadata gives the next page, and is empty on the last one.
+func (stub *Stub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32,
+	bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, nil, err
+	}
+	return stub.paginate(startKey, endKey, pageSize, bookmark)
+}
+
+// GetStateByPartialCompositeKey returns the committed composite keys starting with the given
+// object type and attributes.
+func (stub *Stub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	startKey, endKey, err := partialCompositeKeyRange(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	results, _, versions := stub.ledger.rangeQuery(startKey, endKey, 0)
+	stub.addReads(versions)
+	return &stateIterator{results: results}, nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns a page of GetStateByPartialCompositeKey.
+func (stub *Stub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string,
+	pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	startKey, endKey, err := partialCompositeKeyRange(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stub.paginate(startKey, endKey, pageSize, bookmark)
+}
+
+func (stub *Stub) paginate(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if pageSize <= 0 {
+		return nil, nil, errors.New("the page size must be positive")
+	}
+	if bookmark != "" {
+		if bookmark < startKey || (endKey != "" && bookmark >= endKey) {
+			return nil, nil, fmt.Errorf("the bookmark %q is not in the queried range", bookmark)
+		}
+		startKey = bookmark
+	}
+	results, next, versions := stub.ledger.rangeQuery(startKey, endKey, int(pageSize))
+	stub.addReads(versions)
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: int32(len(results)), Bookmark: next}
+	return &stateIterator{results: results}, metadata, nil
+}
+
+func (stub *Stub) addReads(versions map[string]uint64) {
+	for key, version := range versions {
+		stub.reads[key] = version
+	}
+}
+
+// CreateCompositeKey combines the object type and attributes into a composite key.
+func (stub *Stub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return shim.CreateCompositeKey(objectType, attributes)
+}
+
+// SplitCompositeKey splits a composite key into its object type and attributes.
+func (stub *Stub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	return splitCompositeKey(compositeKey)
+}
+
+// GetQueryResult always fails: like a peer using LevelDB, the ledger has no rich queries.
+func (stub *Stub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errors.New("ExecuteQuery not supported for leveldb")
+}
+
+// GetQueryResultWithPagination always fails: like a peer using LevelDB, the ledger has no rich queries.
+func (stub *Stub) GetQueryResultWithPagination(query string, pageSize int32,
+	bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	return nil, nil, errors.New("ExecuteQuery not supported for leveldb")
+}
+
+// GetHistoryForKey returns the committed modifications of the key, newest first.
+func (stub *Stub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	if key == "" {
+		return nil, errors.New("key must not be an empty string")
+	}
+	return &historyIterator{results: stub.ledger.keyHistory(key)}, nil
+}
+
+// GetPrivateData is not supported.
+func (stub *Stub) GetPrivateData(collection, key string) ([]byte, error) {
+	return nil, errPrivateData
+}
+
+// GetPrivateDataHash is not supported.
+func (stub *Stub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	return nil, errPrivateData
+}
+
+// PutPrivateData is not supported.
+func (stub *Stub) PutPrivateData(collection string, key string, value []byte) error {
+	return errPrivateData
+}
+
+// DelPrivateData is not supported.
+func (stub *Stub) DelPrivateData(collection, key string) error {
+	return errPrivateData
+}
+
+// SetPrivateDataValidationParameter is not supported.
+func (stub *Stub) SetPrivateDataValidationParameter(collection, key string, ep []byte) error {
+	return errPrivateData
+}
+
+// GetPrivateDataValidationParameter is not supported.
+func (stub *Stub) GetPrivateDataValidationParameter(collection, key string) ([]byte, error) {
+	return nil, errPrivateData
+}
+
+// GetPrivateDataByRange is not supported.
+func (stub *Stub) GetPrivateDataByRange(collection, startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errPrivateData
+}
+
+// GetPrivateDataByPartialCompositeKey is not supported.
+func (stub *Stub) GetPrivateDataByPartialCompositeKey(collection, objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errPrivateData
+}
+
+// GetPrivateDataQueryResult is not supported.
+func (stub *Stub) GetPrivateDataQueryResult(collection, query string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errPrivateData
+}
+
+// GetCreator returns the serialized identity of the client.
+func (stub *Stub) GetCreator() ([]byte, error) {
+	return stub.creator, nil
+}
+
+// GetTransient returns the transient data set with SetTransient.
+func (stub *Stub) GetTransient() (map[string][]byte, error) {
+	return stub.transient, nil
+}
+
+// GetBinding returns nil: the transaction has no signed proposal.
+func (stub *Stub) GetBinding() ([]byte, error) {
+	return nil, nil
+}
+
+// GetDecorations returns nil: the transaction has no decorations.
+func (stub *Stub) GetDecorations() map[string][]byte {
+	return nil
+}
+
+// GetSignedProposal returns nil: the transaction has no signed proposal.
+func (stub *Stub) GetSignedProposal() (*peer.SignedProposal, error) {
+	return nil, nil
+}
+
+// GetTxTimestamp returns the timestamp of the transaction, given by the clock of the ledger.
+func (stub *Stub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return stub.timestamp, nil
+}
+
+// SetEvent sets the event of the transaction, replacing the one set before as a peer does.
+func (stub *Stub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return errors.New("event name can not be empty string")
+	}
+	stub.event = &peer.ChaincodeEvent{TxId: stub.txID, EventName: name, Payload: payload}
+	return nil
+}
+
+// stateIterator iterates over the results of a state query.
+type stateIterator struct {
+	results []*queryresult.KV
+	next    int
+}
+
+func (it *stateIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, errors.New("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *stateIterator) Close() error {
+	return nil
+}
+
+// historyIterator iterates over the modifications of a key.
+type historyIterator struct {
+	results []*queryresult.KeyModification
+	next    int
+}
+
+func (it *historyIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, errors.New("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *historyIterator) Close() error {
+	return nil
+}
+
+// partialCompositeKeyRange returns the range of the composite keys starting with the given object
+// type and attributes.
+func partialCompositeKeyRange(objectType string, attributes []string) (string, string, error) {
+	partialCompositeKey, err := shim.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return "", "", err
+	}
+	return partialCompositeKey, partialCompositeKey + string(maxUnicodeRuneValue), nil
+}
+
+func splitCompositeKey(compositeKey string) (string, []string, error) {
+	if len(compositeKey) == 0 || compositeKey[:1] != compositeKeyNamespace {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+	componentIndex := 1
+	components := []string{}
+	for i := 1; i < len(compositeKey); i++ {
+		if compositeKey[i] == minUnicodeRuneValue {
+			components = append(components, compositeKey[componentIndex:i])
+			componentIndex = i + 1
+		}
+	}
+	if len(components) == 0 {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+	return components[0], components[1:], nil
+}
+
+// validateSimpleKeys checks the keys of a range query are not composite keys.
+func validateSimpleKeys(simpleKeys ...string) error {
+	for _, key := range simpleKeys {
+		if len(key) > 0 && key[:1] == compositeKeyNamespace {
+			return fmt.Errorf(`first character of the key [%s] contains a null character which is not allowed`, strconv.Quote(key))
+		}
+	}
+	return nil
+}
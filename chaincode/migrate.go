@@ -0,0 +1,173 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// accountVersion is the format version of the account documents written by this contract.
+//
+// Version history:
+//
+//	0: Balance stored as a float32 number of major units.
+//	1: Balance stored as an integer number of minor units (Amount).
+//	2: Currency added, existing accounts hold DefaultCurrency.
+//	3: Owner added, existing accounts have no owner until MigrateAccounts assigns one.
+//	4: Freeze added, existing accounts are not frozen. Older contracts reject these documents
+//	   instead of dropping the freeze when they rewrite them.
+//	5: Status added, existing accounts are active.
+//	6: Type added, existing accounts are checking accounts.
+const accountVersion = 6
+
+// accountHeader is used to peek at the format version of a stored account document.
+type accountHeader struct {
+	Version int `json:"Version"`
+}
+
+// legacyAccount describes an account document written with format version 0.
+type legacyAccount struct {
+	ID      string  `json:"ID"`
+	Balance float64 `json:"Balance"`
+	Bank    string  `json:"Bank"`
+}
+
+// unmarshalAccount decodes an account document, upgrading documents written by older
+// versions of the contract to the current format.
+func unmarshalAccount(accountJSON []byte) (*Account, error) {
+	var header accountHeader
+	err := json.Unmarshal(accountJSON, &header)
+	if err != nil {
+		return nil, err
+	}
+	if header.Version > accountVersion {
+		return nil, fmt.Errorf("unsupported account format version %d", header.Version)
+	}
+
+	var account Account
+	if header.Version == 0 {
+		var legacy legacyAccount
+		err = json.Unmarshal(accountJSON, &legacy)
+		if err != nil {
+			return nil, err
+		}
+		balance, err := amountFromLegacy(legacy.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert balance of account %s: %v", legacy.ID, err)
+		}
+		account = Account{ID: legacy.ID, Balance: balance, Bank: legacy.Bank}
+	} else {
+		err = json.Unmarshal(accountJSON, &account)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if header.Version < 2 {
+		account.Currency = DefaultCurrency
+	}
+	if header.Version < 5 {
+		account.Status = StatusActive
+	}
+	if header.Version < 6 {
+		account.Type = AccountChecking
+	}
+
+	account.Version = accountVersion
+	return &account, nil
+}
+
+// MigrateAccounts moves every account still stored under its plain ID to its composite key, and
+// rewrites every account stored with an older format version in the current format. Accounts without
+// an owner become owned by the invoking client. The history of a moved account stays reachable through
+// GetAllTxs, which follows its legacy key. It returns the number of migrated accounts.
+func (s *SmartContract) MigrateAccounts(ctx contractapi.TransactionContextInterface) (int, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return 0, err
+	}
+	err = verifyClientRole(ctx, "MigrateAccounts")
+	if err != nil {
+		return 0, err
+	}
+
+	clientID, err := getClientID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Plain keys only hold legacy accounts, they are all moved.
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	migrated := 0
+	for resultsIterator.HasNext() {
+		result, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		account, err := unmarshalAccount(result.Value)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode account %s: %v", result.Key, err)
+		}
+		account.legacyKey = true
+		if err := migrateAccount(ctx, account, clientID); err != nil {
+			return 0, err
+		}
+		migrated++
+	}
+
+	// Namespaced accounts are only rewritten when their format is outdated.
+	accountsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(accountObjectType, []string{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer accountsIterator.Close()
+
+	for accountsIterator.HasNext() {
+		result, err := accountsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		var header accountHeader
+		err = json.Unmarshal(result.Value, &header)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode account %s: %v", result.Key, err)
+		}
+		if header.Version == accountVersion {
+			continue
+		}
+
+		account, err := unmarshalAccount(result.Value)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode account %s: %v", result.Key, err)
+		}
+		if err := migrateAccount(ctx, account, clientID); err != nil {
+			return 0, err
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// migrateAccount writes an account decoded from an older format, giving it the default owner when it has none.
+func migrateAccount(ctx contractapi.TransactionContextInterface, account *Account, defaultOwner string) error {
+	if account.Owner == "" {
+		account.Owner = defaultOwner
+	}
+
+	return putAccount(ctx, account)
+}
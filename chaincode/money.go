@@ -0,0 +1,128 @@
+package chaincode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount is a monetary value expressed as an integer number of minor units of its currency
+// (e.g. cents), so balances never accumulate floating point rounding errors.
+type Amount int64
+
+// DefaultCurrency is the currency of the accounts created before accounts carried a currency.
+const DefaultCurrency = "USD"
+
+// currencyDigits maps the supported ISO 4217 currency codes to their number of decimal places.
+var currencyDigits = map[string]int{
+	"CAD": 2,
+	"CHF": 2,
+	"CUP": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+	"KWD": 3,
+	"MXN": 2,
+	"USD": 2,
+}
+
+// CurrencyDigits returns the number of decimal places allowed by the given currency.
+func CurrencyDigits(currency string) (int, error) {
+	digits, ok := currencyDigits[currency]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %q", currency)
+	}
+	return digits, nil
+}
+
+// ParseAmount parses a non-negative decimal string such as "120" or "50.25" into an Amount of
+// the given currency. Amounts with more decimal places than the currency allows are rejected
+// instead of rounded.
+func ParseAmount(s, currency string) (Amount, error) {
+	digits, err := CurrencyDigits(currency)
+	if err != nil {
+		return 0, err
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" || !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(frac) > digits {
+		return 0, fmt.Errorf("amount %q has more than %d decimal places allowed by %s", s, digits, currency)
+	}
+	frac += strings.Repeat("0", digits-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q is out of range", s)
+	}
+	return Amount(minor), nil
+}
+
+// Format formats the amount as a decimal string followed by the currency code, e.g. "50.25 USD".
+func (a Amount) Format(currency string) string {
+	return a.FormatDecimal(currency) + " " + currency
+}
+
+// FormatDecimal formats the amount as a decimal string with the minor unit digits of the currency,
+// e.g. "50.25" in USD, which ParseAmount reads back. Amounts in unknown currencies are formatted in
+// minor units.
+func (a Amount) FormatDecimal(currency string) string {
+	digits, err := CurrencyDigits(currency)
+	if err != nil {
+		return fmt.Sprintf("%d", int64(a))
+	}
+
+	sign := ""
+	minor := int64(a)
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	if digits == 0 {
+		return fmt.Sprintf("%s%d", sign, minor)
+	}
+	scale := pow10(digits)
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/scale, digits, minor%scale)
+}
+
+// addAmounts returns a + b, failing instead of silently overflowing.
+func addAmounts(a, b Amount) (Amount, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, errors.New("amount overflow")
+	}
+	return a + b, nil
+}
+
+// amountFromLegacy converts a balance stored by the float32 based format into an Amount of
+// DefaultCurrency, rounding to the nearest minor unit to undo accumulated floating point drift.
+func amountFromLegacy(balance float64) (Amount, error) {
+	minor := math.Round(balance * float64(pow10(currencyDigits[DefaultCurrency])))
+	if math.IsNaN(minor) || minor > math.MaxInt64 || minor < math.MinInt64 {
+		return 0, fmt.Errorf("legacy balance %v is out of range", balance)
+	}
+	return Amount(minor), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
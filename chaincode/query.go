@@ -0,0 +1,274 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AccountPage is a page of accounts returned by ListAccounts and QueryAccounts.
+type AccountPage struct {
+	Accounts []*Account `json:"accounts"`
+	// Bookmark is passed to get the next page. It is empty on the last page, and otherwise the ID
+	// of the first account of the next page, except for rich queries, where CouchDB sets it.
+	Bookmark string `json:"bookmark"`
+}
+
+// AccountFilter selects the accounts returned by QueryAccounts. Empty fields match every account.
+type AccountFilter struct {
+	Bank  string
+	Owner string
+	// Currency is required to filter by balance, since balances are compared in its minor units.
+	Currency string
+	// MinBalance and MaxBalance bound the balance, inclusively. A zero MaxBalance leaves the
+	// balance unbounded above.
+	MinBalance Amount
+	MaxBalance Amount
+}
+
+// matches reports whether the account passes the filter.
+func (filter *AccountFilter) matches(account *Account) bool {
+	if filter.Bank != "" && account.Bank != filter.Bank {
+		return false
+	}
+	if filter.Owner != "" && account.Owner != filter.Owner {
+		return false
+	}
+	if filter.Currency != "" && account.Currency != filter.Currency {
+		return false
+	}
+	if account.Balance < filter.MinBalance {
+		return false
+	}
+	if filter.MaxBalance > 0 && account.Balance > filter.MaxBalance {
+		return false
+	}
+	return true
+}
+
+// selector returns the CouchDB query selecting the accounts that pass the filter.
+func (filter *AccountFilter) selector() map[string]interface{} {
+	// Only accounts have both an ID and a Bank.
+	selector := map[string]interface{}{
+		"ID":   map[string]interface{}{"$exists": true},
+		"Bank": map[string]interface{}{"$exists": true},
+	}
+	if filter.Bank != "" {
+		selector["Bank"] = filter.Bank
+	}
+	if filter.Owner != "" {
+		selector["Owner"] = filter.Owner
+	}
+	if filter.Currency != "" {
+		selector["Currency"] = filter.Currency
+	}
+	balance := map[string]interface{}{}
+	if filter.MinBalance > 0 {
+		balance["$gte"] = filter.MinBalance
+	}
+	if filter.MaxBalance > 0 {
+		balance["$lte"] = filter.MaxBalance
+	}
+	if len(balance) > 0 {
+		selector["Balance"] = balance
+	}
+	return selector
+}
+
+// ListAccounts returns a page of at most pageSize accounts, in ID order, starting at the given
+// bookmark. An empty bookmark gets the first page. Accounts still stored under their legacy key
+// are only listed after MigrateAccounts moves them.
+func (s *SmartContract) ListAccounts(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AccountPage, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "ListAccounts")
+	if err != nil {
+		return nil, err
+	}
+
+	if pageSize <= 0 {
+		return nil, newError(CodeInvalidArgument, "the page size must be positive")
+	}
+
+	startKey := ""
+	if bookmark != "" {
+		startKey, err = accountKey(ctx, bookmark)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(accountObjectType, []string{}, pageSize, startKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &AccountPage{Accounts: []*Account{}}
+	if metadata.GetBookmark() != "" {
+		page.Bookmark, err = accountIDFromKey(ctx, metadata.GetBookmark())
+		if err != nil {
+			return nil, err
+		}
+	}
+	for resultsIterator.HasNext() {
+		result, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		account, err := unmarshalAccount(result.Value)
+		if err != nil {
+			return nil, err
+		}
+		page.Accounts = append(page.Accounts, account)
+	}
+
+	return page, nil
+}
+
+// QueryAccounts returns a page of at most pageSize accounts, starting at the given bookmark, that
+// belong to the given bank, owner and currency and whose balance, in minor units of the currency,
+// is between minBalance and maxBalance. Empty strings match every account and a zero maxBalance
+// leaves the balance unbounded above. It runs a rich query when the peer uses CouchDB, and
+// otherwise scans the accounts in ID order; the bookmarks of both are not interchangeable.
+func (s *SmartContract) QueryAccounts(ctx contractapi.TransactionContextInterface, bank, owner, currency string, minBalance, maxBalance int64, pageSize int32, bookmark string) (*AccountPage, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "QueryAccounts")
+	if err != nil {
+		return nil, err
+	}
+
+	filter := AccountFilter{
+		Bank:       bank,
+		Owner:      owner,
+		Currency:   currency,
+		MinBalance: Amount(minBalance),
+		MaxBalance: Amount(maxBalance),
+	}
+	if pageSize <= 0 {
+		return nil, newError(CodeInvalidArgument, "the page size must be positive")
+	}
+	if filter.MinBalance < 0 || filter.MaxBalance < 0 {
+		return nil, newError(CodeInvalidArgument, "the balance bounds must not be negative")
+	}
+	if (filter.MinBalance > 0 || filter.MaxBalance > 0) && filter.Currency == "" {
+		return nil, newError(CodeInvalidArgument, "a currency is required to filter by balance")
+	}
+	if filter.MaxBalance > 0 && filter.MaxBalance < filter.MinBalance {
+		return nil, newError(CodeInvalidArgument, "the maximum balance %d is lower than the minimum balance %d", filter.MaxBalance, filter.MinBalance)
+	}
+
+	query, err := json.Marshal(map[string]interface{}{"selector": filter.selector()})
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(string(query), pageSize, bookmark)
+	if err != nil {
+		if isRichQueryUnsupported(err) {
+			return scanAccounts(ctx, &filter, pageSize, bookmark)
+		}
+		return nil, fmt.Errorf("failed to query world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &AccountPage{Accounts: []*Account{}, Bookmark: metadata.GetBookmark()}
+	for resultsIterator.HasNext() {
+		result, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		account, err := unmarshalAccount(result.Value)
+		if err != nil {
+			return nil, err
+		}
+		page.Accounts = append(page.Accounts, account)
+	}
+	// CouchDB returns the bookmark of the last page too; an empty page marks the end.
+	if len(page.Accounts) < int(pageSize) {
+		page.Bookmark = ""
+	}
+
+	return page, nil
+}
+
+// scanAccounts filters the accounts in ID order, starting at the bookmark, which is the ID of the
+// first account of the page. The accounts of a bank are found through the bank index. It is used
+// on peers without rich queries.
+func scanAccounts(ctx contractapi.TransactionContextInterface, filter *AccountFilter, pageSize int32, bookmark string) (*AccountPage, error) {
+	objectType, attributes := accountObjectType, []string{}
+	if filter.Bank != "" {
+		objectType, attributes = accountByBankIndex, []string{filter.Bank}
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &AccountPage{Accounts: []*Account{}}
+	for resultsIterator.HasNext() {
+		result, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		accountID, err := accountIDFromKey(ctx, result.Key)
+		if err != nil {
+			return nil, err
+		}
+		if accountID < bookmark {
+			continue
+		}
+		var account *Account
+		if filter.Bank != "" {
+			account, err = getAccount(ctx, accountID)
+			if err != nil {
+				return nil, err
+			}
+			if account == nil {
+				return nil, fmt.Errorf("the bank index refers to the missing account %s", accountID)
+			}
+		} else {
+			account, err = unmarshalAccount(result.Value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !filter.matches(account) {
+			continue
+		}
+		if len(page.Accounts) == int(pageSize) {
+			page.Bookmark = accountID
+			break
+		}
+		page.Accounts = append(page.Accounts, account)
+	}
+
+	return page, nil
+}
+
+// isRichQueryUnsupported reports whether the error is the one returned by peers using LevelDB,
+// which have no rich queries.
+func isRichQueryUnsupported(err error) bool {
+	return strings.Contains(err.Error(), "not supported for leveldb")
+}
@@ -1,9 +1,8 @@
 package chaincode
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/ptypes"
@@ -17,11 +16,34 @@ type SmartContract struct {
 
 // Account describes basic details of what makes up a simple account
 type Account struct {
-	ID      string  `json:"ID"`
-	Balance float32 `json:"Balance"`
-	Bank    string  `json:"Bank"`
+	ID       string `json:"ID"`
+	Balance  Amount `json:"Balance"`
+	Currency string `json:"Currency"`
+	Bank     string `json:"Bank"`
+	Owner    string `json:"Owner"`
+	Version  int    `json:"Version"`
+	// Type is the product of the account: checking, savings or business.
+	Type string `json:"Type"`
+	// Status is the status of the account in its lifecycle: pending, active, dormant or closed.
+	Status string `json:"Status"`
+	// Freeze is the compliance hold on the account, nil when it is not frozen.
+	Freeze *AccountFreeze `json:"Freeze,omitempty" metadata:"Freeze,optional"`
+
+	// legacyKey is set on accounts read from the plain ID key used before accounts were namespaced.
+	legacyKey bool
 }
 
+// Types of account.
+const (
+	// AccountChecking is a current account, and the type of the accounts created before there
+	// were types.
+	AccountChecking = "checking"
+	// AccountSavings is a savings account.
+	AccountSavings = "savings"
+	// AccountBusiness is the account of a business.
+	AccountBusiness = "business"
+)
+
 // TxRecord structure used to return the transaction history result of an account
 type TxRecord struct {
 	Record    *Account  `json:"record"`
@@ -30,27 +52,122 @@ type TxRecord struct {
 	IsDelete  bool      `json:"isDelete"`
 }
 
-// InitLedger adds a base set of accounts to the ledger
-func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
+// SeedAccount describes an account created by InitLedger. The balance is expressed in minor units,
+// and accounts without a type are checking accounts.
+type SeedAccount struct {
+	ID       string `json:"ID"`
+	Balance  Amount `json:"Balance"`
+	Currency string `json:"Currency"`
+	Bank     string `json:"Bank"`
+	Type     string `json:"Type,omitempty" metadata:"Type,optional"`
+}
+
+// defaultSeedAccounts are the accounts created by InitLedger when no seed accounts are given.
+var defaultSeedAccounts = []SeedAccount{
+	{ID: "account1", Balance: 10000, Currency: "USD", Bank: "JPMorgan Chase & Co."},
+	{ID: "account2", Balance: 20000, Currency: "USD", Bank: "Bank of America Corp."},
+	{ID: "account3", Balance: 30000, Currency: "EUR", Bank: "JPMorgan Chase & Co."},
+	{ID: "account4", Balance: 40000, Currency: "USD", Bank: "Bank of America Corp."},
+	{ID: "account5", Balance: 50000, Currency: "EUR", Bank: "JPMorgan Chase & Co."},
+}
+
+// InitLedger adds the given seed accounts, owned by the invoking client, to the ledger. When no seed
+// accounts are given a default set is used. It refuses to run when any seed account already exists,
+// unless force is set, in which case those accounts are reset to their seed state.
+func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface, seed []SeedAccount, force bool) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "InitLedger")
+	if err != nil {
+		return err
+	}
+
+	clientID, err := getClientID(ctx)
+	if err != nil {
+		return err
+	}
 
-	accounts := []Account{
-		{ID: "account1", Balance: 100, Bank: "JPMorgan Chase & Co."},
-		{ID: "account2", Balance: 200, Bank: "Bank of America Corp."},
-		{ID: "account3", Balance: 300, Bank: "JPMorgan Chase & Co."},
-		{ID: "account4", Balance: 400, Bank: "Bank of America Corp."},
-		{ID: "account5", Balance: 500, Bank: "JPMorgan Chase & Co."},
+	if len(seed) == 0 {
+		seed = defaultSeedAccounts
 	}
 
-	// For each account encoding and save it
-	for _, account := range accounts {
-		accountJSON, err := json.Marshal(account)
+	// Validate every seed account before writing any of them
+	seen := make(map[string]bool)
+	existing := make(map[string]*Account)
+	var existingIDs []string
+	for _, account := range seed {
+		if account.ID == "" {
+			return newError(CodeInvalidArgument, "seed accounts must have an ID")
+		}
+		if seen[account.ID] {
+			return newError(CodeInvalidArgument, "the seed account %s is repeated", account.ID)
+		}
+		seen[account.ID] = true
+		if account.Balance < 0 {
+			return newError(CodeInvalidArgument, "the seed account %s has a negative balance", account.ID)
+		}
+		if _, err := CurrencyDigits(account.Currency); err != nil {
+			return newError(CodeInvalidArgument, "the seed account %s is invalid: %v", account.ID, err)
+		}
+		if account.Type != "" {
+			if err := verifyAccountType(account.Type); err != nil {
+				return newError(CodeInvalidArgument, "the seed account %s is invalid: %v", account.ID, err)
+			}
+		}
+
+		current, err := getAccount(ctx, account.ID)
 		if err != nil {
 			return err
 		}
+		if current != nil && current.Freeze != nil {
+			return newError(CodeAccountFrozen, "the seed account %s is frozen and cannot be reset", account.ID)
+		}
+		if current != nil && current.Status == StatusClosed {
+			return newError(CodeFailedPrecondition, "the seed account %s is closed and cannot be reset", account.ID)
+		}
+		if current != nil {
+			existing[account.ID] = current
+			existingIDs = append(existingIDs, account.ID)
+		}
+	}
+	if len(existingIDs) > 0 && !force {
+		return newError(CodeAlreadyExists, "the seed accounts %s already exist, force a reset to overwrite them", strings.Join(existingIDs, ", "))
+	}
+
+	// For each account encoding and save it
+	for _, seedAccount := range seed {
+		account := Account{
+			ID:       seedAccount.ID,
+			Balance:  seedAccount.Balance,
+			Currency: seedAccount.Currency,
+			Bank:     seedAccount.Bank,
+			Owner:    clientID,
+			Version:  accountVersion,
+			Type:     seedAccount.Type,
+			Status:   StatusActive,
+		}
+		if account.Type == "" {
+			account.Type = AccountChecking
+		}
+		// A reset account may move to another bank, so its old index entry is removed first.
+		if current, ok := existing[account.ID]; ok {
+			if err := delAccount(ctx, current); err != nil {
+				return err
+			}
+			account.legacyKey = current.legacyKey
+		}
 
-		err = ctx.GetStub().PutState(account.ID, accountJSON)
+		err = putAccount(ctx, &account)
 		if err != nil {
-			return fmt.Errorf("failed to put to world state. %v", err)
+			return err
 		}
 	}
 
@@ -60,191 +177,408 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 // AccountExists returns true when account with given ID exists in world state
 func (s *SmartContract) AccountExists(ctx contractapi.TransactionContextInterface, accountID string) (bool, error) {
 
-	// Get client org id and verify it matches peer org id.
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
 	clientOrgID, err := getClientOrgID(ctx)
 	if err != nil {
 		return false, err
 	}
-	err = verifyClientOrgMatchesPeerOrg(clientOrgID)
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return false, err
+	}
+	err = verifyClientRole(ctx, "AccountExists")
 	if err != nil {
 		return false, err
 	}
 
-	accountJSON, err := ctx.GetStub().GetState(accountID)
+	account, err := getAccount(ctx, accountID)
 	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %v", err)
+		return false, err
 	}
-	return accountJSON != nil, nil
+	return account != nil, nil
 }
 
 // ReadAccount returns the account stored in the world state with given id.
 func (s *SmartContract) ReadAccount(ctx contractapi.TransactionContextInterface, accountID string) (*Account, error) {
 
-	// Get client org id and verify it matches peer org id.
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
 	clientOrgID, err := getClientOrgID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	err = verifyClientOrgMatchesPeerOrg(clientOrgID)
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
 	if err != nil {
 		return nil, err
 	}
-
-	accountJSON, err := ctx.GetStub().GetState(accountID)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
-	}
-	if accountJSON == nil {
-		return nil, fmt.Errorf("the account %s does not exist", accountID)
-	}
-
-	var account Account
-	err = json.Unmarshal(accountJSON, &account)
+	err = verifyClientRole(ctx, "ReadAccount")
 	if err != nil {
 		return nil, err
 	}
 
-	return &account, nil
+	return getExistingAccount(ctx, accountID)
 }
 
 // CreateAccount issues a new account to the world state with given details.
-func (s *SmartContract) CreateAccount(ctx contractapi.TransactionContextInterface, id string, balance float32, bank string) error {
+// The balance is expressed in minor units of the given currency and the invoking client becomes the owner.
+// The type of the account is checking, savings or business.
+func (s *SmartContract) CreateAccount(ctx contractapi.TransactionContextInterface, id string, balance int64, bank string, currency string, accountType string) error {
 
-	// Get client org id and verify it matches peer org id.
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
 	clientOrgID, err := getClientOrgID(ctx)
 	if err != nil {
 		return err
 	}
-	err = verifyClientOrgMatchesPeerOrg(clientOrgID)
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "CreateAccount")
 	if err != nil {
 		return err
 	}
 
-	exists, err := s.AccountExists(ctx, id)
+	if id == "" {
+		return newError(CodeInvalidArgument, "the account ID must not be empty")
+	}
+	if balance < 0 {
+		return newError(CodeInvalidArgument, "balance must not be negative")
+	}
+	if _, err := CurrencyDigits(currency); err != nil {
+		return newError(CodeInvalidArgument, "%v", err)
+	}
+	if err := verifyAccountType(accountType); err != nil {
+		return newError(CodeInvalidArgument, "%v", err)
+	}
 
+	current, err := getAccount(ctx, id)
 	if err != nil {
 		return err
 	}
-	if exists {
-		return fmt.Errorf("the account %s already exists", id)
+	if current != nil && current.Status == StatusClosed {
+		return newError(CodeAlreadyExists, "the account %s was closed and its ID cannot be reused", id)
 	}
-
-	account := Account{
-		ID:      id,
-		Balance: balance,
-		Bank:    bank,
+	if current != nil {
+		return newError(CodeAlreadyExists, "the account %s already exists", id)
 	}
 
-	accountJSON, err := json.Marshal(account)
+	clientID, err := getClientID(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = ctx.GetStub().PutState(account.ID, accountJSON)
+	account := Account{
+		ID:       id,
+		Balance:  Amount(balance),
+		Currency: currency,
+		Bank:     bank,
+		Owner:    clientID,
+		Version:  accountVersion,
+		Type:     accountType,
+		Status:   StatusActive,
+	}
+
+	err = putAccount(ctx, &account)
 	if err != nil {
-		return fmt.Errorf("failed to put asset in public data: %v", err)
+		return err
 	}
 
 	// Set the endorsement policy such that an owner org peer is required to endorse future updates
+	key, err := accountKey(ctx, account.ID)
+	if err != nil {
+		return err
+	}
 	endorsingOrgs := []string{clientOrgID}
-	err = setAssetStateBasedEndorsement(ctx, account.ID, endorsingOrgs)
+	err = setAssetStateBasedEndorsement(ctx, key, endorsingOrgs)
 	if err != nil {
 		return fmt.Errorf("failed setting state based endorsement for buyer and seller: %v", err)
 	}
 
-	return nil
+	return emitEvent(ctx, EventAccountCreated, newAccountEvent(&account))
 }
 
-// DeleteAccount deletes an given account from the world state.
+// DeleteAccount closes an account with a zero balance, as CloseAccount does without a sweep. Accounts
+// are no longer removed from the world state, so that their IDs are not reused.
 func (s *SmartContract) DeleteAccount(ctx contractapi.TransactionContextInterface, accountID string) error {
 
-	// Get client org id and verify it matches peer org id.
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "DeleteAccount")
+	if err != nil {
+		return err
+	}
+
+	return closeAccount(ctx, accountID, "")
+}
+
+// TransferOwnership makes the client with the given ID the owner of an account.
+// Only the current owner of the account can transfer its ownership.
+func (s *SmartContract) TransferOwnership(ctx contractapi.TransactionContextInterface, accountID, newOwner string) error {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
 	clientOrgID, err := getClientOrgID(ctx)
 	if err != nil {
 		return err
 	}
-	err = verifyClientOrgMatchesPeerOrg(clientOrgID)
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientRole(ctx, "TransferOwnership")
 	if err != nil {
 		return err
 	}
 
-	exists, err := s.AccountExists(ctx, accountID)
+	if newOwner == "" {
+		return newError(CodeInvalidArgument, "the new owner must not be empty")
+	}
 
+	account, err := getExistingAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	err = verifyClientIsAccountOwner(ctx, account)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		return fmt.Errorf("the account %s does not exist", accountID)
+	err = verifyNotClosed(account)
+	if err != nil {
+		return err
 	}
 
-	return ctx.GetStub().DelState(accountID)
+	account.Owner = newOwner
+
+	return putAccount(ctx, account)
+}
+
+// GetClientID returns the ID of the invoking client, as stored in the Owner field of its accounts.
+func (s *SmartContract) GetClientID(ctx contractapi.TransactionContextInterface) (string, error) {
+	return getClientID(ctx)
 }
 
-func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, fromId, toId string, amount float32) error {
-	// @todo q solo pueda hacer esto el duenyo d la cuenta fuente
+// Transfer moves the given amount, expressed in minor units, from one account to another.
+// Both accounts must hold the same currency and only the owner of the source account can transfer.
+// The transfer is recorded with the given memo, which may be empty, and its record is returned.
+// The amount must be within the limits of the source account, see SetAccountLimits. The fee of the
+// transfer, set by the fee schedule, is debited from the source account on top of the amount and
+// credited to the fee collector account.
+func (s *SmartContract) Transfer(ctx contractapi.TransactionContextInterface, fromId, toId string, amount int64, memo string) (*TransferRecord, error) {
 
-	// Get client org id and verify it matches peer org id.
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
 	clientOrgID, err := getClientOrgID(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = verifyClientOrgMatchesPeerOrg(clientOrgID)
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "Transfer")
+	if err != nil {
+		return nil, err
 	}
 
 	if amount <= 0 {
-		return errors.New("amount must be positive")
+		return nil, newError(CodeInvalidArgument, "amount must be positive")
+	}
+	if fromId == toId {
+		return nil, newError(CodeInvalidArgument, "the source and destination accounts must differ")
+	}
+	err = verifyMemo(memo)
+	if err != nil {
+		return nil, err
 	}
 
-	fromAcc, err := s.ReadAccount(ctx, fromId)
+	fromAcc, err := getExistingAccount(ctx, fromId)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientIsAccountOwner(ctx, fromAcc)
 	if err != nil {
-		return errors.New("the source account doesn't exist")
+		return nil, err
+	}
+	err = verifyCanDebit(fromAcc)
+	if err != nil {
+		return nil, err
 	}
 
-	toAcc, err := s.ReadAccount(ctx, toId)
+	toAcc, err := getExistingAccount(ctx, toId)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyCanCredit(toAcc)
 	if err != nil {
-		return errors.New("the destination account doesn't exist")
+		return nil, err
 	}
 
-	if fromAcc.Balance-amount < 0 {
-		return errors.New("the source account does not have enough balance")
+	if fromAcc.Currency != toAcc.Currency {
+		return nil, newError(CodeFailedPrecondition, "cannot transfer between accounts in different currencies (%s and %s), use TransferWithConversion instead", fromAcc.Currency, toAcc.Currency)
 	}
 
-	fromAcc.Balance -= amount
-	toAcc.Balance += amount
+	fee, collectorID, err := getTransferFee(ctx, fromAcc, Amount(amount))
+	if err != nil {
+		return nil, err
+	}
+	total, err := addAmounts(Amount(amount), fee)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "the amount plus its fee is out of range")
+	}
+	if fromAcc.Balance < total {
+		if fee > 0 {
+			return nil, newError(CodeInsufficientFunds, "the source account does not have enough balance for the amount and its fee of %s", fee.Format(fromAcc.Currency))
+		}
+		return nil, newError(CodeInsufficientFunds, "the source account does not have enough balance")
+	}
 
-	fromAccJson, err := json.Marshal(fromAcc)
+	toBalance, err := addAmounts(toAcc.Balance, Amount(amount))
 	if err != nil {
-		return err
+		return nil, newError(CodeFailedPrecondition, "the destination account cannot hold the amount: %v", err)
 	}
-	if err := ctx.GetStub().PutState(fromId, fromAccJson); err != nil {
-		return err
+
+	// The fee collector may be the destination account, which is then credited with both.
+	var collector *Account
+	collectorBalance := toBalance
+	if fee > 0 {
+		collector = toAcc
+		if collectorID != toAcc.ID {
+			collector, err = getExistingAccount(ctx, collectorID)
+			if err != nil {
+				return nil, err
+			}
+			err = verifyCanCredit(collector)
+			if err != nil {
+				return nil, err
+			}
+			collectorBalance = collector.Balance
+		}
+		collectorBalance, err = addAmounts(collectorBalance, fee)
+		if err != nil {
+			return nil, newError(CodeFailedPrecondition, "the fee collector account cannot hold the fee: %v", err)
+		}
 	}
 
-	toAccJson, err := json.Marshal(toAcc)
+	err = verifyDebitLimits(ctx, fromAcc, Amount(amount))
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	fromAcc.Balance -= total
+	toAcc.Balance = toBalance
+	if collector != nil {
+		collector.Balance = collectorBalance
 	}
 
-	return ctx.GetStub().PutState(toId, toAccJson)
+	if err := putAccount(ctx, fromAcc); err != nil {
+		return nil, err
+	}
+	if err := putAccount(ctx, toAcc); err != nil {
+		return nil, err
+	}
+	if collector != nil && collector != toAcc {
+		if err := putAccount(ctx, collector); err != nil {
+			return nil, err
+		}
+	}
+
+	transfer, err := newTransferRecord(ctx, fromAcc, toAcc, Amount(amount), Amount(amount), memo)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Fee = fee
+	transfer.FeeCollector = collectorID
+	if err := putTransfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	err = emitEvent(ctx, EventFundsTransferred, &TransferEvent{
+		TransferID:   transfer.ID,
+		FromID:       fromId,
+		ToID:         toId,
+		Amount:       Amount(amount),
+		Currency:     fromAcc.Currency,
+		ToAmount:     Amount(amount),
+		ToCurrency:   toAcc.Currency,
+		FromBalance:  fromAcc.Balance,
+		ToBalance:    toAcc.Balance,
+		Fee:          fee,
+		FeeCollector: collectorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
 }
 
+// GetAllTxs returns every state the given account has gone through. It fails with NOT_FOUND when
+// the account never existed.
 func (s *SmartContract) GetAllTxs(ctx contractapi.TransactionContextInterface, accountID string) ([]TxRecord, error) {
 
-	// Get client org id and verify it matches peer org id.
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
 	clientOrgID, err := getClientOrgID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	err = verifyClientOrgMatchesPeerOrg(clientOrgID)
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "GetAllTxs")
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := getAccountTxs(ctx, accountID)
 	if err != nil {
 		return nil, err
 	}
+	if len(records) == 0 {
+		return nil, newError(CodeNotFound, "the account %s does not exist", accountID)
+	}
+
+	return records, nil
+}
 
-	// Get the transaction history result of an account
-	historyIterator, err := ctx.GetStub().GetHistoryForKey(accountID)
+// getAccountTxs returns the states of an account, newest first: the history of its key, followed
+// by the history of its legacy key, leaving out the deletion of the legacy key when the account
+// was moved to its composite key.
+func getAccountTxs(ctx contractapi.TransactionContextInterface, accountID string) ([]TxRecord, error) {
+	key, err := accountKey(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := getAccountHistory(ctx, key, accountID)
+	if err != nil {
+		return nil, err
+	}
+	legacyRecords, err := getAccountHistory(ctx, accountID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := make(map[string]bool)
+	for _, record := range records {
+		moved[record.TxId] = true
+	}
+	for _, record := range legacyRecords {
+		if record.IsDelete && moved[record.TxId] {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// getAccountHistory returns the states of the account with the given ID stored under the given key.
+func getAccountHistory(ctx contractapi.TransactionContextInterface, key, accountID string) ([]TxRecord, error) {
+	historyIterator, err := ctx.GetStub().GetHistoryForKey(key)
 	if err != nil {
 		return nil, err
 	}
@@ -257,16 +591,14 @@ func (s *SmartContract) GetAllTxs(ctx contractapi.TransactionContextInterface, a
 			return nil, err
 		}
 
-		var account Account
+		account := &Account{
+			ID: accountID,
+		}
 		if len(response.Value) > 0 {
-			err = json.Unmarshal(response.Value, &account)
+			account, err = unmarshalAccount(response.Value)
 			if err != nil {
 				return nil, err
 			}
-		} else {
-			account = Account{
-				ID: accountID,
-			}
 		}
 
 		timestamp, err := ptypes.Timestamp(response.Timestamp)
@@ -277,7 +609,7 @@ func (s *SmartContract) GetAllTxs(ctx contractapi.TransactionContextInterface, a
 		record := TxRecord{
 			TxId:      response.TxId,
 			Timestamp: timestamp,
-			Record:    &account,
+			Record:    account,
 			IsDelete:  response.IsDelete,
 		}
 		records = append(records, record)
@@ -285,3 +617,12 @@ func (s *SmartContract) GetAllTxs(ctx contractapi.TransactionContextInterface, a
 
 	return records, nil
 }
+
+// verifyAccountType checks that the type of an account is one of the known ones.
+func verifyAccountType(accountType string) error {
+	switch accountType {
+	case AccountChecking, AccountSavings, AccountBusiness:
+		return nil
+	}
+	return fmt.Errorf("invalid account type %q, it must be %s, %s or %s", accountType, AccountChecking, AccountSavings, AccountBusiness)
+}
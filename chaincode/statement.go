@@ -0,0 +1,237 @@
+package chaincode
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Kinds of the entries of a statement.
+const (
+	// EntryOpened is the creation of the account, credited with its initial balance.
+	EntryOpened = "opened"
+	// EntryTransfer is a transfer from or to another account.
+	EntryTransfer = "transfer"
+	// EntryBatch is a batch of transfers involving the account more than once.
+	EntryBatch = "batch"
+	// EntryFee is the fee of a transfer between other accounts, credited to its collector.
+	EntryFee = "fee"
+	// EntryEscrow is an amount locked in an escrow, debited from its payer.
+	EntryEscrow = "escrow"
+	// EntryEscrowRefund is the refund of an expired escrow, credited to its payer.
+	EntryEscrowRefund = "escrow refund"
+	// EntryAdjustment is any other change of the balance, such as InitLedger recreating the account.
+	EntryAdjustment = "adjustment"
+	// EntryDeleted is the deletion of the account, debited with its remaining balance.
+	EntryDeleted = "deleted"
+)
+
+// Statement is the statement of an account over a period, derived from the history of the account.
+// Amounts are expressed in minor units of Currency. From and To are zero when the period is
+// unbounded on that side.
+type Statement struct {
+	AccountID      string            `json:"AccountID"`
+	Currency       string            `json:"Currency"`
+	From           time.Time         `json:"From"`
+	To             time.Time         `json:"To"`
+	OpeningBalance Amount            `json:"OpeningBalance"`
+	TotalCredits   Amount            `json:"TotalCredits"`
+	TotalDebits    Amount            `json:"TotalDebits"`
+	ClosingBalance Amount            `json:"ClosingBalance"`
+	Entries        []*StatementEntry `json:"Entries"`
+}
+
+// StatementEntry is a change of the balance of an account. Amount is positive for credits and
+// negative for debits, and Balance is the balance after the change. Counterparty and Memo are only
+// set for transfers.
+type StatementEntry struct {
+	TxID         string    `json:"TxID"`
+	Timestamp    time.Time `json:"Timestamp"`
+	Kind         string    `json:"Kind"`
+	Counterparty string    `json:"Counterparty,omitempty" metadata:"Counterparty,optional"`
+	Memo         string    `json:"Memo,omitempty" metadata:"Memo,optional"`
+	Amount       Amount    `json:"Amount"`
+	Balance      Amount    `json:"Balance"`
+}
+
+// GetStatement returns the statement of the given account for the transactions committed from
+// the from time, inclusive, to the to time, exclusive. Both are RFC 3339 timestamps, and an empty
+// one leaves the period unbounded on that side. The opening balance is the balance at the start of
+// the period.
+func (s *SmartContract) GetStatement(ctx contractapi.TransactionContextInterface, accountID, from, to string) (*Statement, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "GetStatement")
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Statement{AccountID: accountID, Entries: []*StatementEntry{}}
+	statement.From, err = parseStatementTime(from)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "invalid start of the period: %v", err)
+	}
+	statement.To, err = parseStatementTime(to)
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "invalid end of the period: %v", err)
+	}
+	if !statement.From.IsZero() && !statement.To.IsZero() && !statement.From.Before(statement.To) {
+		return nil, newError(CodeInvalidArgument, "the period must end after it starts")
+	}
+
+	records, err := getAccountTxs(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, newError(CodeNotFound, "the account %s does not exist", accountID)
+	}
+	// The history is newest first; the statement needs it in the order it happened.
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+
+	var balance Amount
+	exists := false
+	for _, record := range records {
+		entry := &StatementEntry{TxID: record.TxId, Timestamp: record.Timestamp}
+		switch {
+		case record.IsDelete:
+			if !exists {
+				continue
+			}
+			entry.Kind = EntryDeleted
+			entry.Amount = -balance
+			entry.Balance = 0
+			exists = false
+		case !exists:
+			entry.Kind = EntryOpened
+			entry.Amount = record.Record.Balance
+			entry.Balance = record.Record.Balance
+			exists = true
+		default:
+			entry.Amount = record.Record.Balance - balance
+			entry.Balance = record.Record.Balance
+			if entry.Amount == 0 {
+				// Changes of owner and migrations leave the balance as it was.
+				continue
+			}
+			entry.Kind = EntryAdjustment
+			err = describeTransfer(ctx, accountID, entry)
+			if err != nil {
+				return nil, err
+			}
+			if entry.Kind == EntryAdjustment {
+				err = describeEscrow(ctx, accountID, entry)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if !record.IsDelete {
+			statement.Currency = record.Record.Currency
+		}
+		balance = entry.Balance
+
+		if record.Timestamp.Before(statement.From) {
+			statement.OpeningBalance = balance
+			continue
+		}
+		if !statement.To.IsZero() && !record.Timestamp.Before(statement.To) {
+			break
+		}
+		if entry.Amount > 0 {
+			statement.TotalCredits, err = addAmounts(statement.TotalCredits, entry.Amount)
+		} else {
+			statement.TotalDebits, err = addAmounts(statement.TotalDebits, -entry.Amount)
+		}
+		if err != nil {
+			return nil, err
+		}
+		statement.Entries = append(statement.Entries, entry)
+	}
+
+	statement.ClosingBalance = statement.OpeningBalance
+	if len(statement.Entries) > 0 {
+		statement.ClosingBalance = statement.Entries[len(statement.Entries)-1].Balance
+	}
+
+	return statement, nil
+}
+
+// describeTransfer marks the entry as a transfer, with its counterparty and memo, when its
+// transaction recorded a transfer involving the account, or as a batch when it recorded several.
+func describeTransfer(ctx contractapi.TransactionContextInterface, accountID string, entry *StatementEntry) error {
+	transfers, err := getTxTransfers(ctx, accountID, entry.TxID, entry.Timestamp)
+	if err != nil {
+		return err
+	}
+	if len(transfers) == 0 {
+		return nil
+	}
+	if len(transfers) > 1 {
+		entry.Kind = EntryBatch
+		return nil
+	}
+
+	transfer := transfers[0]
+	switch accountID {
+	case transfer.FromID:
+		entry.Counterparty = transfer.ToID
+	case transfer.ToID:
+		entry.Counterparty = transfer.FromID
+	case transfer.FeeCollector:
+		entry.Kind = EntryFee
+		entry.Counterparty = transfer.FromID
+		return nil
+	default:
+		return nil
+	}
+	entry.Kind = EntryTransfer
+	entry.Memo = transfer.Memo
+	return nil
+}
+
+// describeEscrow marks the entry as an escrow, with the payee as its counterparty, when its
+// transaction created or refunded an escrow paid by the account.
+func describeEscrow(ctx contractapi.TransactionContextInterface, accountID string, entry *StatementEntry) error {
+	escrow, err := getEscrow(ctx, entry.TxID)
+	if err != nil {
+		return err
+	}
+	kind := EntryEscrow
+	if escrow == nil {
+		escrow, err = getRefundedEscrow(ctx, entry.TxID)
+		if err != nil {
+			return err
+		}
+		kind = EntryEscrowRefund
+	}
+	if escrow == nil || escrow.FromID != accountID {
+		return nil
+	}
+	entry.Kind = kind
+	entry.Counterparty = escrow.ToID
+	return nil
+}
+
+// parseStatementTime parses a bound of the period of a statement, returning the zero time for an
+// empty one.
+func parseStatementTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,321 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Transfers are stored under the composite key transfer/[id], and indexed by the accounts they
+// involve, including the collector of their fee, under transfer~account/[account ID, timestamp, id],
+// so that the transfers of an account are listed in the order they happened. The ID of a transfer is
+// the ID of its transaction.
+const (
+	transferObjectType     = "transfer"
+	transferByAccountIndex = "transfer~account"
+	// transferIndexTimeLayout formats the timestamps of the index so that they sort in time order.
+	transferIndexTimeLayout = "20060102T150405.000000000Z"
+	// maxMemoLength is the maximum length, in bytes, of the memo of a transfer.
+	maxMemoLength = 256
+)
+
+// TransferRecord records a transfer between two accounts. Amount is debited from the source account
+// in its currency and ToAmount is credited to the destination account in its currency; they only
+// differ for transfers with conversion, which also record the rate applied. Fee is debited from the
+// source account on top of Amount and credited to the FeeCollector account.
+type TransferRecord struct {
+	ID         string    `json:"ID"`
+	FromID     string    `json:"FromID"`
+	ToID       string    `json:"ToID"`
+	Amount     Amount    `json:"Amount"`
+	Currency   string    `json:"Currency"`
+	ToAmount   Amount    `json:"ToAmount"`
+	ToCurrency string    `json:"ToCurrency"`
+	Rate       string    `json:"Rate,omitempty" metadata:"Rate,optional"`
+	Memo       string    `json:"Memo"`
+	Initiator  string    `json:"Initiator"`
+	Timestamp  time.Time `json:"Timestamp"`
+	TxID       string    `json:"TxID"`
+	// Fee and FeeCollector are empty for free transfers.
+	Fee          Amount `json:"Fee,omitempty" metadata:"Fee,optional"`
+	FeeCollector string `json:"FeeCollector,omitempty" metadata:"FeeCollector,optional"`
+}
+
+// TransferPage is a page of transfers returned by ListTransfersForAccount.
+type TransferPage struct {
+	Transfers []*TransferRecord `json:"transfers"`
+	// Bookmark is passed to get the next page. It is empty on the last page, and otherwise the ID
+	// of the first transfer of the next page.
+	Bookmark string `json:"bookmark"`
+}
+
+// GetTransfer returns the transfer with the given ID.
+func (s *SmartContract) GetTransfer(ctx contractapi.TransactionContextInterface, transferID string) (*TransferRecord, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "GetTransfer")
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := getTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, newError(CodeNotFound, "the transfer %s does not exist", transferID)
+	}
+
+	return transfer, nil
+}
+
+// ListTransfersForAccount returns a page of at most pageSize transfers from or to the given account,
+// in the order they happened, starting at the given bookmark. An empty bookmark gets the first page.
+// Transfers made before transfers were recorded are not listed. It fails with NOT_FOUND when the
+// account never existed.
+func (s *SmartContract) ListTransfersForAccount(ctx contractapi.TransactionContextInterface, accountID string, pageSize int32, bookmark string) (*TransferPage, error) {
+
+	// Get client org id, verify it matches peer org id and that the client role is allowed.
+	clientOrgID, err := getClientOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientOrgMatchesPeerOrg(ctx, clientOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyClientRole(ctx, "ListTransfersForAccount")
+	if err != nil {
+		return nil, err
+	}
+
+	if pageSize <= 0 {
+		return nil, newError(CodeInvalidArgument, "the page size must be positive")
+	}
+
+	startKey := ""
+	if bookmark != "" {
+		transfer, err := getTransfer(ctx, bookmark)
+		if err != nil {
+			return nil, err
+		}
+		if transfer == nil {
+			return nil, newError(CodeInvalidArgument, "invalid bookmark: the transfer %s does not exist", bookmark)
+		}
+		if !transfer.involves(accountID) {
+			return nil, newError(CodeInvalidArgument, "invalid bookmark: the transfer %s does not involve the account %s", bookmark, accountID)
+		}
+		startKey, err = transferAccountIndexKey(ctx, accountID, transfer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(transferByAccountIndex, []string{accountID}, pageSize, startKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &TransferPage{Transfers: []*TransferRecord{}}
+	if metadata.GetBookmark() != "" {
+		page.Bookmark, err = transferIDFromKey(ctx, metadata.GetBookmark())
+		if err != nil {
+			return nil, err
+		}
+	}
+	for resultsIterator.HasNext() {
+		result, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		transferID, err := transferIDFromKey(ctx, result.Key)
+		if err != nil {
+			return nil, err
+		}
+		transfer, err := getTransfer(ctx, transferID)
+		if err != nil {
+			return nil, err
+		}
+		if transfer == nil {
+			return nil, fmt.Errorf("the transfer index refers to the missing transfer %s", transferID)
+		}
+		page.Transfers = append(page.Transfers, transfer)
+	}
+
+	// An account without transfers is told apart from one that never existed by its history.
+	if bookmark == "" && len(page.Transfers) == 0 {
+		records, err := getAccountTxs(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, newError(CodeNotFound, "the account %s does not exist", accountID)
+		}
+	}
+
+	return page, nil
+}
+
+// newTransferRecord returns the record of a transfer made by the current transaction.
+func newTransferRecord(ctx contractapi.TransactionContextInterface, fromAcc, toAcc *Account, amount, toAmount Amount, memo string) (*TransferRecord, error) {
+	initiator, err := getClientID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := getTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txID := ctx.GetStub().GetTxID()
+	return &TransferRecord{
+		ID:         txID,
+		FromID:     fromAcc.ID,
+		ToID:       toAcc.ID,
+		Amount:     amount,
+		Currency:   fromAcc.Currency,
+		ToAmount:   toAmount,
+		ToCurrency: toAcc.Currency,
+		Memo:       memo,
+		Initiator:  initiator,
+		Timestamp:  timestamp,
+		TxID:       txID,
+	}, nil
+}
+
+// verifyMemo checks that the memo of a transfer is not too long.
+func verifyMemo(memo string) error {
+	if len(memo) > maxMemoLength {
+		return newError(CodeInvalidArgument, "the memo is %d bytes long, the maximum is %d", len(memo), maxMemoLength)
+	}
+	return nil
+}
+
+// getTransfer reads the transfer with the given ID from the world state. It returns nil when the
+// transfer does not exist.
+func getTransfer(ctx contractapi.TransactionContextInterface, transferID string) (*TransferRecord, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(transferObjectType, []string{transferID})
+	if err != nil {
+		return nil, newError(CodeInvalidArgument, "invalid transfer ID %q: %v", transferID, err)
+	}
+	transferJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if transferJSON == nil {
+		return nil, nil
+	}
+
+	var transfer TransferRecord
+	err = json.Unmarshal(transferJSON, &transfer)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// putTransfer writes the transfer and the index entries of its accounts to the world state.
+func putTransfer(ctx contractapi.TransactionContextInterface, transfer *TransferRecord) error {
+	key, err := ctx.GetStub().CreateCompositeKey(transferObjectType, []string{transfer.ID})
+	if err != nil {
+		return fmt.Errorf("invalid transfer ID %q: %v", transfer.ID, err)
+	}
+	transferJSON, err := json.Marshal(transfer)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, transferJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put to world state. %v", err)
+	}
+
+	accountIDs := []string{transfer.FromID, transfer.ToID}
+	if transfer.FeeCollector != "" && transfer.FeeCollector != transfer.ToID {
+		accountIDs = append(accountIDs, transfer.FeeCollector)
+	}
+	for _, accountID := range accountIDs {
+		indexKey, err := transferAccountIndexKey(ctx, accountID, transfer)
+		if err != nil {
+			return err
+		}
+		err = ctx.GetStub().PutState(indexKey, indexValue)
+		if err != nil {
+			return fmt.Errorf("failed to put to world state. %v", err)
+		}
+	}
+
+	return nil
+}
+
+// involves reports whether the transfer moves money from or to the given account, including its fee.
+func (transfer *TransferRecord) involves(accountID string) bool {
+	return transfer.FromID == accountID || transfer.ToID == accountID || transfer.FeeCollector == accountID
+}
+
+// getTxTransfers returns the transfers of the given account recorded by the transaction with the
+// given ID and timestamp, in the order they were made.
+func getTxTransfers(ctx contractapi.TransactionContextInterface, accountID, txID string, timestamp time.Time) ([]*TransferRecord, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(transferByAccountIndex, []string{
+		accountID,
+		timestamp.UTC().Format(transferIndexTimeLayout),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	var transfers []*TransferRecord
+	for resultsIterator.HasNext() {
+		result, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		transferID, err := transferIDFromKey(ctx, result.Key)
+		if err != nil {
+			return nil, err
+		}
+		transfer, err := getTransfer(ctx, transferID)
+		if err != nil {
+			return nil, err
+		}
+		if transfer != nil && transfer.TxID == txID {
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	return transfers, nil
+}
+
+// transferAccountIndexKey returns the key of the index entry of a transfer for one of its accounts.
+func transferAccountIndexKey(ctx contractapi.TransactionContextInterface, accountID string, transfer *TransferRecord) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(transferByAccountIndex, []string{
+		accountID,
+		transfer.Timestamp.UTC().Format(transferIndexTimeLayout),
+		transfer.ID,
+	})
+}
+
+// transferIDFromKey returns the transfer ID of a transfer index entry key.
+func transferIDFromKey(ctx contractapi.TransactionContextInterface, key string) (string, error) {
+	_, attributes, err := ctx.GetStub().SplitCompositeKey(key)
+	if err != nil {
+		return "", err
+	}
+	if len(attributes) != 3 {
+		return "", fmt.Errorf("the key %q is not a transfer index entry", key)
+	}
+
+	return attributes[2], nil
+}
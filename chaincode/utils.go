@@ -2,14 +2,16 @@ package chaincode
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
-// setAssetStateBasedEndorsement adds an endorsement policy to an asset so that the passed orgs need to agree upon transfer
-func setAssetStateBasedEndorsement(ctx contractapi.TransactionContextInterface, accountID string, orgsToEndorse []string) error {
+// setAssetStateBasedEndorsement adds an endorsement policy to the asset stored under the given key so that the passed orgs need to agree upon transfer
+func setAssetStateBasedEndorsement(ctx contractapi.TransactionContextInterface, key string, orgsToEndorse []string) error {
 	endorsementPolicy, err := statebased.NewStateEP(nil)
 	if err != nil {
 		return err
@@ -22,7 +24,7 @@ func setAssetStateBasedEndorsement(ctx contractapi.TransactionContextInterface,
 	if err != nil {
 		return fmt.Errorf("failed to create endorsement policy bytes from org: %v", err)
 	}
-	err = ctx.GetStub().SetStateValidationParameter(accountID, policy)
+	err = ctx.GetStub().SetStateValidationParameter(key, policy)
 	if err != nil {
 		return fmt.Errorf("failed to set validation parameter on asset: %v", err)
 	}
@@ -40,15 +42,58 @@ func getClientOrgID(ctx contractapi.TransactionContextInterface) (string, error)
 	return clientOrgID, nil
 }
 
+// getClientID gets the ID of the client's X.509 identity.
+func getClientID(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed getting client's ID: %v", err)
+	}
+
+	return clientID, nil
+}
+
+// verifyClientIsAccountOwner checks the client is the owner of the given account.
+func verifyClientIsAccountOwner(ctx contractapi.TransactionContextInterface, account *Account) error {
+	clientID, err := getClientID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if account.Owner == "" {
+		return newError(CodeFailedPrecondition, "the account %s has no owner, it must be migrated with MigrateAccounts", account.ID)
+	}
+	if clientID != account.Owner {
+		return newError(CodeUnauthorized, "client is not the owner of the account %s", account.ID)
+	}
+
+	return nil
+}
+
+// peerMSPIDProvider is implemented by stubs that are not run by a peer, such as the in-memory
+// ledger's, to give the MSP ID of the peer they stand for.
+type peerMSPIDProvider interface {
+	GetPeerMSPID() (string, error)
+}
+
+// getPeerOrgID gets the peer org ID, from the stub when it provides one and otherwise from the
+// peer's environment.
+func getPeerOrgID(ctx contractapi.TransactionContextInterface) (string, error) {
+	if provider, ok := ctx.GetStub().(peerMSPIDProvider); ok {
+		return provider.GetPeerMSPID()
+	}
+
+	return shim.GetMSPID()
+}
+
 // verifyClientOrgMatchesPeerOrg checks the client org id matches the peer org id.
-func verifyClientOrgMatchesPeerOrg(clientOrgID string) error {
-	peerOrgID, err := shim.GetMSPID()
+func verifyClientOrgMatchesPeerOrg(ctx contractapi.TransactionContextInterface, clientOrgID string) error {
+	peerOrgID, err := getPeerOrgID(ctx)
 	if err != nil {
 		return fmt.Errorf("failed getting peer's orgID: %v", err)
 	}
 
 	if clientOrgID != peerOrgID {
-		return fmt.Errorf("client from org %s is not authorized to read or write private data from an org %s peer",
+		return newError(CodeUnauthorized, "client from org %s is not authorized to read or write private data from an org %s peer",
 			clientOrgID,
 			peerOrgID,
 		)
@@ -56,3 +101,24 @@ func verifyClientOrgMatchesPeerOrg(clientOrgID string) error {
 
 	return nil
 }
+
+// getTxTimestamp returns the timestamp of the transaction, which is the same on every endorsing peer.
+func getTxTimestamp(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed getting transaction timestamp: %v", err)
+	}
+
+	return ptypes.Timestamp(txTimestamp)
+}
+
+// containsString reports whether values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// Names of the backends selected by Options.Backend.
+const (
+	// BackendGateway runs the transactions on a Fabric network through the Fabric Gateway.
+	BackendGateway = "gateway"
+	// BackendSim runs the contract in process against an in-memory ledger, optionally saved to a file.
+	BackendSim = "sim"
+)
+
+// Backend runs the transactions of a HyperPayContract. The context of the calls may be ignored:
+// HyperPayContract already stops waiting for a call when its context is done.
+type Backend interface {
+	// Submit submits a transaction to the ledger and returns its result.
+	Submit(ctx context.Context, name string, args ...string) ([]byte, error)
+	// Evaluate evaluates a transaction without submitting it and returns its result.
+	Evaluate(ctx context.Context, name string, args ...string) ([]byte, error)
+	// Events returns a channel receiving the chaincode events committed from now on. The channel
+	// is closed when ctx is done.
+	Events(ctx context.Context) (<-chan *fab.CCEvent, error)
+	// Close releases the resources of the backend.
+	Close() error
+}
+
+// newBackend returns the backend selected by the options.
+func newBackend(opts Options) (Backend, error) {
+	switch opts.Backend {
+	case BackendGateway:
+		return newGatewayBackend(opts)
+	case BackendSim:
+		return newSimBackend(opts)
+	default:
+		return nil, fmt.Errorf("unknown backend %q, it must be %s or %s", opts.Backend, BackendGateway, BackendSim)
+	}
+}
+
+// openWallet opens the wallet of the options, importing the identity from the MSP directory when
+// it is not in the wallet yet.
+func openWallet(opts Options) (*gateway.Wallet, error) {
+	wallet, err := gateway.NewFileSystemWallet(opts.WalletPath)
+	if err != nil {
+		return nil, err
+	}
+	if !wallet.Exists(opts.Identity) {
+		if err := ImportIdentity(wallet, opts.Identity, opts.MSPDir, opts.MSPID); err != nil {
+			return nil, err
+		}
+	}
+	return wallet, nil
+}
+
+// gatewayBackend runs the transactions on a Fabric network.
+type gatewayBackend struct {
+	gw *gateway.Gateway
+	c  *gateway.Contract
+}
+
+func newGatewayBackend(opts Options) (*gatewayBackend, error) {
+	err := os.Setenv("DISCOVERY_AS_LOCALHOST", "true")
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := openWallet(opts)
+	if err != nil {
+		return nil, err
+	}
+	gw, err := gateway.Connect(
+		gateway.WithConfig(config.FromFile(filepath.Clean(opts.CCPPath))),
+		gateway.WithIdentity(wallet, opts.Identity),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	network, err := gw.GetNetwork(opts.ChannelID)
+	if err != nil {
+		gw.Close()
+		return nil, err
+	}
+	return &gatewayBackend{gw: gw, c: network.GetContract(opts.ChaincodeName)}, nil
+}
+
+func (backend *gatewayBackend) Submit(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return backend.c.SubmitTransaction(name, args...)
+}
+
+func (backend *gatewayBackend) Evaluate(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return backend.c.EvaluateTransaction(name, args...)
+}
+
+func (backend *gatewayBackend) Events(ctx context.Context) (<-chan *fab.CCEvent, error) {
+	registration, notifier, err := backend.c.RegisterEvent(".*")
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan *fab.CCEvent)
+	go func() {
+		defer close(events)
+		defer backend.c.Unregister(registration)
+		for {
+			select {
+			case event, ok := <-notifier:
+				if !ok {
+					return
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (backend *gatewayBackend) Close() error {
+	backend.gw.Close()
+	return nil
+}
@@ -0,0 +1,256 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchFile   string
+	batchFrom   string
+	batchDryRun bool
+)
+
+// batchRow is a transfer of a batch file, numbered from 1 after the header.
+type batchRow struct {
+	Row    int
+	FromID string
+	ToID   string
+	Amount string
+	Memo   string
+}
+
+// batchFailure is a row of a batch file that would fail, reported by a dry run.
+type batchFailure struct {
+	Row     int                 `json:"Row"`
+	FromID  string              `json:"FromID"`
+	ToID    string              `json:"ToID"`
+	Amount  string              `json:"Amount"`
+	Code    chaincode.ErrorCode `json:"Code"`
+	Message string              `json:"Message"`
+}
+
+// batchTransferCmd represents the batch-transfer command
+var batchTransferCmd = &cobra.Command{
+	Use:   "batch-transfer",
+	Args:  cobra.NoArgs,
+	Short: "Makes the transfers of a CSV file in a single transaction",
+	Long: `Makes the transfers of the CSV file given by --file in a single transaction, so that
+			either all of them are made or none is.
+			The file has a header naming its columns: from, to, amount and memo, in any order.
+			The from column can be left out when --from gives the source account of every
+			transfer, and the memo column is optional. Amounts are written in the currency of
+			the source account, e.g.
+				to,amount,memo
+				account2,1250.00,Payroll March
+			Each transfer is charged the fee of the fee schedule, as with transfer.
+			With --dry-run nothing is transferred, and the rows that would fail are printed
+			with the reason.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		verifyOutputFormat(outputFormats...)
+		rows, err := readBatchFile(batchFile, batchFrom)
+		if err != nil {
+			exitErr(exitFailure, "Failed to read batch file", err)
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		// The amounts are written in the currency of their source account.
+		var items []chaincode.BatchTransferItem
+		var itemRows []*batchRow
+		failures := []*batchFailure{}
+		sources := make(map[string]*chaincode.Account)
+		sourceErrs := make(map[string]*chaincode.Error)
+		for _, row := range rows {
+			source, ok := sources[row.FromID]
+			if !ok && sourceErrs[row.FromID] == nil {
+				source, err = contract.Read(ctx, row.FromID)
+				var contractErr *chaincode.Error
+				if err != nil && (!batchDryRun || !errors.As(err, &contractErr)) {
+					exitErr(exitRejected, "Failed to evaluate transaction", err)
+				}
+				sources[row.FromID], sourceErrs[row.FromID] = source, contractErr
+			}
+			if contractErr := sourceErrs[row.FromID]; contractErr != nil {
+				failures = append(failures, newBatchFailure(row, contractErr.Code, contractErr.Message))
+				continue
+			}
+			amount, err := chaincode.ParseAmount(row.Amount, source.Currency)
+			if err != nil {
+				if !batchDryRun {
+					exitErr(exitUsage, fmt.Sprintf("Invalid amount in row %d", row.Row), err)
+				}
+				failures = append(failures, newBatchFailure(row, chaincode.CodeInvalidArgument, err.Error()))
+				continue
+			}
+			items = append(items, chaincode.BatchTransferItem{FromID: row.FromID, ToID: row.ToID, Amount: amount, Memo: row.Memo})
+			itemRows = append(itemRows, row)
+		}
+
+		if !batchDryRun {
+			log.Println("--> Submit Transaction: BatchTransfer, function makes the transfers of a batch in a single transaction")
+			records, err := contract.BatchTransfer(ctx, items)
+			if err != nil {
+				exitErr(exitRejected, "Failed to submit transaction, nothing was transferred", err)
+			}
+			table := [][]string{{"TRANSFER", "FROM", "TO", "AMOUNT", "FEE", "CURRENCY", "MEMO"}}
+			for _, record := range records {
+				table = append(table, []string{record.ID, record.FromID, record.ToID, record.Amount.FormatDecimal(record.Currency),
+					record.Fee.FormatDecimal(record.Currency), record.Currency, record.Memo})
+			}
+			printResult(records, table)
+			return
+		}
+
+		if len(items) > 0 {
+			log.Println("--> Evaluate Transaction: CheckBatchTransfer, function checks the transfers of a batch without making them")
+			checked, err := contract.CheckBatchTransfer(ctx, items)
+			if err != nil {
+				exitErr(exitRejected, "Failed to evaluate transaction", err)
+			}
+			for _, failure := range checked {
+				failures = append(failures, newBatchFailure(itemRows[failure.Index], failure.Code, failure.Message))
+			}
+		}
+		sort.Slice(failures, func(i, j int) bool {
+			return failures[i].Row < failures[j].Row
+		})
+		table := [][]string{{"ROW", "FROM", "TO", "AMOUNT", "CODE", "MESSAGE"}}
+		for _, failure := range failures {
+			table = append(table, []string{strconv.Itoa(failure.Row), failure.FromID, failure.ToID, failure.Amount, string(failure.Code), failure.Message})
+		}
+		printResult(failures, table)
+		if len(failures) > 0 {
+			exitf(exitRejected, "%d of the %d transfers would fail", len(failures), len(rows))
+		}
+		log.Printf("All the %d transfers would succeed", len(rows))
+	},
+}
+
+// newBatchFailure returns the failure of a row of a batch file.
+func newBatchFailure(row *batchRow, code chaincode.ErrorCode, message string) *batchFailure {
+	return &batchFailure{
+		Row:     row.Row,
+		FromID:  row.FromID,
+		ToID:    row.ToID,
+		Amount:  row.Amount,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// readBatchFile reads the transfers of a CSV batch file. Rows without a source account take the
+// given default one.
+func readBatchFile(path, defaultFrom string) ([]*batchRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"to", "amount"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the file has no %s column", name)
+		}
+	}
+	if _, ok := columns["from"]; !ok && defaultFrom == "" {
+		return nil, errors.New("the file has no from column and --from is not set")
+	}
+
+	var rows []*batchRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := &batchRow{
+			Row:    len(rows) + 1,
+			FromID: field("from"),
+			ToID:   field("to"),
+			Amount: field("amount"),
+			Memo:   field("memo"),
+		}
+		if row.FromID == "" {
+			row.FromID = defaultFrom
+		}
+		if row.FromID == "" || row.ToID == "" {
+			return nil, fmt.Errorf("row %d has no source or destination account", row.Row)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("the file has no transfers")
+	}
+	return rows, nil
+}
+
+func init() {
+	rootCmd.AddCommand(batchTransferCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// batchTransferCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// batchTransferCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	batchTransferCmd.Flags().StringVar(&batchFile, "file", "", "CSV file with the transfers to make")
+	batchTransferCmd.Flags().StringVar(&batchFrom, "from", "", "source account of the rows without one")
+	batchTransferCmd.Flags().BoolVar(&batchDryRun, "dry-run", false, "only print the rows that would fail")
+	batchTransferCmd.MarkFlagRequired("file")
+}
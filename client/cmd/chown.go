@@ -0,0 +1,62 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// chownCmd represents the chown command
+var chownCmd = &cobra.Command{
+	Use:   "chown <account-id> <new-owner-id>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Transfers the ownership of the given account",
+	Long: `Transfers the ownership of the given account.
+			Receives an account id and the client ID of the new owner, as printed by whoami.
+			Only the current owner of the account can transfer its ownership.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		newOwner := args[1]
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: TransferOwnership, function changes the owner of an account")
+		if err := contract.TransferOwnership(ctx, id, newOwner); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(chownCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// chownCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// chownCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
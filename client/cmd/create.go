@@ -16,31 +16,49 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
 	"log"
 
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
 	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
 	"github.com/spf13/cobra"
 )
 
+var (
+	createCurrency string
+	createType     string
+)
+
 // createCmd represents the create command
 var createCmd = &cobra.Command{
-	Use:   "create",
+	Use:   "create <account-id> <balance> <bank>",
+	Args:  cobra.ExactArgs(3),
 	Short: "Creates an account with the given id, balance and bank information",
 	Long: `Creates an account with the given id, balance and bank information.
-			Receives id, balance and bank and create a new account with the given details.`,
+			Receives id, balance and bank and create a new account with the given details.
+			The account holds the currency given by the --currency flag, and is of the type
+			given by --type: checking, savings or business.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		id := args[0]
-		var balance float32
-		_, err := fmt.Sscan(args[1], &balance)
+		balance, err := chaincode.ParseAmount(args[1], createCurrency)
+		if err != nil {
+			exitErr(exitUsage, "Invalid balance", err)
+		}
 		bank := args[2]
-		contract, err := client.NewHyperPayContract()
+		switch createType {
+		case chaincode.AccountChecking, chaincode.AccountSavings, chaincode.AccountBusiness:
+		default:
+			exitf(exitUsage, "Invalid account type %q, it must be checking, savings or business", createType)
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
 		if err != nil {
-			log.Fatalf("Failed to create contract client: %v", err)
+			exitErr(exitUnavailable, "Failed to create contract client", err)
 		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
 		log.Println("--> Submit Transaction: CreateAccount, function create a new account to the world state with given details")
-		if err := contract.Create(id, balance, bank); err != nil {
-			log.Fatalf("Failed to submit transaction: %v", err)
+		if err := contract.Create(ctx, id, balance, bank, createCurrency, createType); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
 		}
 	},
 }
@@ -57,4 +75,6 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// createCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	createCmd.Flags().StringVar(&createCurrency, "currency", chaincode.DefaultCurrency, "ISO 4217 code of the currency held by the account")
+	createCmd.Flags().StringVar(&createType, "type", chaincode.AccountChecking, "type of the account: checking, savings or business")
 }
@@ -16,31 +16,66 @@ limitations under the License.
 package cmd
 
 import (
+	"bufio"
+	"fmt"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
 	"github.com/spf13/cobra"
 )
 
+var (
+	deleteSweepTo string
+	deleteYes     bool
+)
+
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
-	Use:   "delete",
-	Short: "Deletes the given account",
-	Long: `Deletes the given account.
-	Receives an account and delete it.`,
+	Use:     "delete <account-id>",
+	Aliases: []string{"close"},
+	Args:    cobra.ExactArgs(1),
+	Short:   "Closes the given account",
+	Long: `Closes the given account.
+	Receives an account and closes it. The account is kept as closed, so that its ID cannot
+	be used again. An account holding funds is only closed when --sweep-to names the
+	account its whole balance is transferred to, in the same transaction.
+	It asks for confirmation unless --yes is given.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		id := args[0]
-		contract, err := client.NewHyperPayContract()
+		if !deleteYes && !confirm(fmt.Sprintf("Close the account %s? Its ID cannot be used again.", id)) {
+			exitf(exitFailure, "The account was not closed")
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
 		if err != nil {
-			log.Fatalf("Failed to create contract client: %v", err)
+			exitErr(exitUnavailable, "Failed to create contract client", err)
 		}
-		log.Println("--> Submit Transaction: DeleteAccount, function deletes the account with the given id from the world state")
-		if err := contract.Delete(id); err != nil {
-			log.Fatalf("Failed to submit transaction: %v", err)
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: CloseAccount, function closes the account with the given id")
+		if err := contract.CloseAccount(ctx, id, deleteSweepTo); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
 		}
 	},
 }
 
+// confirm asks the question on stderr and reports whether the answer read from stdin is yes.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(os.Stderr)
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
 func init() {
 	rootCmd.AddCommand(deleteCmd)
 
@@ -53,4 +88,6 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// deleteCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	deleteCmd.Flags().StringVar(&deleteSweepTo, "sweep-to", "", "account the balance is transferred to before closing")
+	deleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "close without asking for confirmation")
 }
@@ -0,0 +1,43 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// escrowCmd represents the escrow command
+var escrowCmd = &cobra.Command{
+	Use:   "escrow",
+	Short: "Manages hash time-locked escrow payments",
+	Long: `Manages hash time-locked escrow payments.
+			An escrow locks funds of the payer until the payee claims them with the secret
+			whose SHA-256 hash locks the escrow, or returns them to the payer once it expires.`,
+}
+
+func init() {
+	rootCmd.AddCommand(escrowCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// escrowCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// escrowCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// escrowClaimCmd represents the escrow claim command
+var escrowClaimCmd = &cobra.Command{
+	Use:   "claim <escrow-id> <secret>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Releases the funds of the given escrow to its payee",
+	Long: `Releases the funds of the given escrow to its payee, given the hex encoded secret
+			whose SHA-256 hash locks it. The escrow must not have expired.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		secret := args[1]
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: ClaimEscrow, function releases the funds of an escrow to its payee")
+		escrow, err := contract.ClaimEscrow(ctx, id, secret)
+		if err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+		printResult(escrow, escrowRows(escrow))
+	},
+}
+
+func init() {
+	escrowCmd.AddCommand(escrowClaimCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// escrowClaimCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// escrowClaimCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
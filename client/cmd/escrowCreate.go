@@ -0,0 +1,117 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	escrowHashlock string
+	escrowExpiry   string
+)
+
+// escrowCreateCmd represents the escrow create command
+var escrowCreateCmd = &cobra.Command{
+	Use:   "create <payer-id> <payee-id> <amount>",
+	Args:  cobra.ExactArgs(3),
+	Short: "Locks the given amount of the payer account in an escrow for the payee account",
+	Long: `Locks the given amount of the payer account in an escrow for the payee account.
+			The escrow is locked by the SHA-256 hash given by --hashlock, hex encoded; without it
+			a random secret is generated and printed with the escrow once it is created, to be
+			shared with the payee when the deal is settled. The escrow expires at the time given
+			by --expiry, either an RFC 3339 time or a duration from now such as 48h.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		payer := args[0]
+		payee := args[1]
+		expiry, err := parseEscrowExpiry(escrowExpiry)
+		if err != nil {
+			exitErr(exitUsage, "Invalid expiry", err)
+		}
+		hashlock := escrowHashlock
+		// The generated secret is only printed once the escrow is created.
+		var secret string
+		if hashlock == "" {
+			preimage := make([]byte, sha256.Size)
+			if _, err := rand.Read(preimage); err != nil {
+				exitErr(exitFailure, "Failed to generate the secret", err)
+			}
+			hash := sha256.Sum256(preimage)
+			hashlock = hex.EncodeToString(hash[:])
+			secret = hex.EncodeToString(preimage)
+		}
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		// The amount is written in the currency of the payer account.
+		payerAcc, err := contract.Read(ctx, payer)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		amount, err := chaincode.ParseAmount(args[2], payerAcc.Currency)
+		if err != nil {
+			exitErr(exitUsage, "Invalid amount", err)
+		}
+		log.Println("--> Submit Transaction: CreateEscrow, function locks funds of an account in an escrow")
+		escrow, err := contract.CreateEscrow(ctx, payer, payee, amount, hashlock, expiry)
+		if err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+		if secret != "" {
+			log.Println("--> Keep the secret of the escrow until the deal is settled")
+			escrow.Preimage = secret
+		}
+		printResult(escrow, escrowRows(escrow))
+	},
+}
+
+// parseEscrowExpiry parses the expiry of an escrow, an RFC 3339 time or a duration from now.
+func parseEscrowExpiry(value string) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(duration), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func init() {
+	escrowCmd.AddCommand(escrowCreateCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// escrowCreateCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// escrowCreateCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	escrowCreateCmd.Flags().StringVar(&escrowHashlock, "hashlock", "", "hex encoded SHA-256 hash of the secret that claims the escrow")
+	escrowCreateCmd.Flags().StringVar(&escrowExpiry, "expiry", "", "RFC 3339 time or duration from now after which the escrow can only be refunded")
+	escrowCreateCmd.MarkFlagRequired("expiry")
+}
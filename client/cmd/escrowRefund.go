@@ -0,0 +1,63 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// escrowRefundCmd represents the escrow refund command
+var escrowRefundCmd = &cobra.Command{
+	Use:   "refund <escrow-id>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Returns the funds of the given expired escrow to its payer",
+	Long: `Returns the funds of the given escrow to its payer.
+			The escrow must have expired without being claimed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: RefundEscrow, function returns the funds of an expired escrow to its payer")
+		escrow, err := contract.RefundEscrow(ctx, id)
+		if err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+		printResult(escrow, escrowRows(escrow))
+	},
+}
+
+func init() {
+	escrowCmd.AddCommand(escrowRefundCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// escrowRefundCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// escrowRefundCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
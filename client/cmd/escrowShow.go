@@ -0,0 +1,74 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// escrowShowCmd represents the escrow show command
+var escrowShowCmd = &cobra.Command{
+	Use:   "show <escrow-id>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Shows the given escrow",
+	Long: `Shows the given escrow, with its status: locked, claimed or refunded.
+			The secret of a claimed escrow is revealed with it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Evaluate Transaction: GetEscrow, function returns an escrow")
+		escrow, err := contract.Escrow(ctx, id)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		printResult(escrow, escrowRows(escrow))
+	},
+}
+
+// escrowRows returns the table rows describing an escrow.
+func escrowRows(escrow *chaincode.Escrow) [][]string {
+	return [][]string{
+		{"ESCROW", "FROM", "TO", "AMOUNT", "CURRENCY", "STATUS", "EXPIRY", "SECRET"},
+		{escrow.ID, escrow.FromID, escrow.ToID, escrow.Amount.FormatDecimal(escrow.Currency), escrow.Currency,
+			escrow.Status, escrow.Expiry.Format(time.RFC3339), escrow.Preimage},
+	}
+}
+
+func init() {
+	escrowCmd.AddCommand(escrowShowCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// escrowShowCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// escrowShowCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -17,32 +17,47 @@ package cmd
 
 import (
 	"log"
+	"os"
+	"strconv"
 
 	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
 	"github.com/spf13/cobra"
 )
 
+// existsResult is the result printed by the exists command.
+type existsResult struct {
+	ID     string `json:"ID"`
+	Exists bool   `json:"Exists"`
+}
+
 // existsCmd represents the exists command
 var existsCmd = &cobra.Command{
-	Use:   "exists",
+	Use:   "exists <account-id>",
+	Args:  cobra.ExactArgs(1),
 	Short: "Determines whether an account with the given ID exists",
 	Long: `Determines whether an account with the given ID exists.
-			Receives an account id.`,
+			Receives an account id. The command exits with status 3 when the account does not exist.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		id := args[0]
-		contract, err := client.NewHyperPayContract()
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
 		if err != nil {
-			log.Fatalf("Failed to create contract client: %v", err)
+			exitErr(exitUnavailable, "Failed to create contract client", err)
 		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
 		log.Println("--> Evaluate Transaction: AccountExists, function returns true if the given account exists in the world state")
-		exists, err := contract.Exists(id)
+		exists, err := contract.Exists(ctx, id)
 		if err != nil {
-			log.Fatalf("Failed to evaluate transaction: %v", err)
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
 		}
-		if exists {
-			log.Println("The account " + id + " exists")
-		} else {
-			log.Println("The account " + id + " doesn't exists")
+		printResult(&existsResult{ID: id, Exists: exists}, [][]string{
+			{"ID", "EXISTS"},
+			{id, strconv.FormatBool(exists)},
+		})
+		if !exists {
+			os.Exit(exitNotFound)
 		}
 	},
 }
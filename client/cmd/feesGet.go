@@ -0,0 +1,94 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// feesGetCmd represents the fees get command
+var feesGetCmd = &cobra.Command{
+	Use:   "get",
+	Args:  cobra.NoArgs,
+	Short: "Shows the fee schedule stored on the ledger",
+	Long: `Shows the rules of the fee schedule stored on the ledger, in the order they are
+			checked. The first rule matching the bank and type of the source account and the
+			currency of a transfer sets its fee.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Evaluate Transaction: GetFeeSchedule, function returns the fee schedule")
+		schedule, err := contract.Fees(ctx)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		rows := [][]string{{"RULE", "BANK", "TYPE", "CURRENCY", "COLLECTOR", "FLAT", "PERCENT", "TIERS", "MIN", "MAX"}}
+		for i, rule := range schedule.Rules {
+			currency := rule.Currency
+			var tiers []string
+			for _, tier := range rule.Tiers {
+				tiers = append(tiers, fmt.Sprintf("from %s: %s + %s%%", tier.From.FormatDecimal(currency), tier.Flat.FormatDecimal(currency), feePercent(tier.Percent)))
+			}
+			rows = append(rows, []string{
+				strconv.Itoa(i),
+				rule.Bank,
+				rule.AccountType,
+				currency,
+				rule.Collector,
+				rule.Flat.FormatDecimal(currency),
+				feePercent(rule.Percent),
+				strings.Join(tiers, "; "),
+				rule.Min.FormatDecimal(currency),
+				rule.Max.FormatDecimal(currency),
+			})
+		}
+		printResult(schedule, rows)
+	},
+}
+
+// feePercent returns the percentage of a fee rule or tier, which is empty when it is zero.
+func feePercent(percent string) string {
+	if percent == "" {
+		return "0"
+	}
+	return percent
+}
+
+func init() {
+	feesCmd.AddCommand(feesGetCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// feesGetCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// feesGetCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,155 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+var feesFile string
+
+// feeFileRule is a rule of a fee schedule file, whose amounts are decimal strings such as "0.50" in
+// the currency of the rule. Empty amounts are zero.
+type feeFileRule struct {
+	Bank        string        `json:"Bank"`
+	AccountType string        `json:"AccountType"`
+	Currency    string        `json:"Currency"`
+	Collector   string        `json:"Collector"`
+	Flat        string        `json:"Flat"`
+	Percent     string        `json:"Percent"`
+	Tiers       []feeFileTier `json:"Tiers"`
+	Min         string        `json:"Min"`
+	Max         string        `json:"Max"`
+}
+
+// feeFileTier is a tier of a rule of a fee schedule file.
+type feeFileTier struct {
+	From    string `json:"From"`
+	Flat    string `json:"Flat"`
+	Percent string `json:"Percent"`
+}
+
+// feesSetCmd represents the fees set command
+var feesSetCmd = &cobra.Command{
+	Use:   "set",
+	Args:  cobra.NoArgs,
+	Short: "Replaces the fee schedule stored on the ledger",
+	Long: `Replaces the fee schedule stored on the ledger with the rules of the JSON array
+			given by --file, e.g. [{"Bank": "BCC", "AccountType": "savings", "Currency": "USD",
+			"Collector": "fees-usd", "Flat": "0.50", "Percent": "0.1", "Min": "1", "Max": "25"}].
+			Amounts are written in the currency of the rule, and a rule may replace its flat
+			fee and percentage by tiers, e.g. "Tiers": [{"From": "1000", "Percent": "0.05"}].
+			An empty array stops charging fees. Only admins can run it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rules, err := readFeeFile(feesFile)
+		if err != nil {
+			exitErr(exitFailure, "Failed to read fee schedule file", err)
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: SetFeeSchedule, function replaces the fee schedule")
+		if err := contract.SetFees(ctx, rules); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+	},
+}
+
+// readFeeFile reads the rules of the given fee schedule file.
+func readFeeFile(path string) ([]chaincode.FeeRule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fileRules []feeFileRule
+	err = json.Unmarshal(data, &fileRules)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]chaincode.FeeRule, len(fileRules))
+	for i, fileRule := range fileRules {
+		rule := chaincode.FeeRule{
+			Bank:        fileRule.Bank,
+			AccountType: fileRule.AccountType,
+			Currency:    fileRule.Currency,
+			Collector:   fileRule.Collector,
+			Percent:     fileRule.Percent,
+		}
+		for _, amount := range []struct {
+			value  string
+			amount *chaincode.Amount
+		}{
+			{fileRule.Flat, &rule.Flat},
+			{fileRule.Min, &rule.Min},
+			{fileRule.Max, &rule.Max},
+		} {
+			*amount.amount, err = parseFeeAmount(amount.value, fileRule.Currency)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %v", i, err)
+			}
+		}
+		for _, fileTier := range fileRule.Tiers {
+			tier := chaincode.FeeTier{Percent: fileTier.Percent}
+			tier.From, err = parseFeeAmount(fileTier.From, fileRule.Currency)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %v", i, err)
+			}
+			tier.Flat, err = parseFeeAmount(fileTier.Flat, fileRule.Currency)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %v", i, err)
+			}
+			rule.Tiers = append(rule.Tiers, tier)
+		}
+		rules[i] = rule
+	}
+	return rules, nil
+}
+
+// parseFeeAmount parses an amount of a fee schedule file, where an empty amount is zero.
+func parseFeeAmount(value, currency string) (chaincode.Amount, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return chaincode.ParseAmount(value, currency)
+}
+
+func init() {
+	feesCmd.AddCommand(feesSetCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// feesSetCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// feesSetCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	feesSetCmd.Flags().StringVar(&feesFile, "file", "", "JSON file with the rules of the fee schedule")
+	feesSetCmd.MarkFlagRequired("file")
+}
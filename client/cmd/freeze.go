@@ -0,0 +1,76 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	freezeMode   string
+	freezeReason string
+)
+
+// freezeCmd represents the freeze command
+var freezeCmd = &cobra.Command{
+	Use:   "freeze <account-id>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Freezes the given account",
+	Long: `Freezes the given account, stopping its money movements while it is under investigation.
+			The --mode flag sets what is stopped: debit (money leaving the account), credit
+			(money entering it) or full (both). The --reason flag is a code recorded with the
+			freeze, e.g. AML_REVIEW. Only admins and compliance officers can freeze accounts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		switch freezeMode {
+		case chaincode.FreezeDebit, chaincode.FreezeCredit, chaincode.FreezeFull:
+		default:
+			exitf(exitUsage, "Invalid --mode %q, it must be debit, credit or full", freezeMode)
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: FreezeAccount, function freezes an account")
+		if err := contract.Freeze(ctx, id, freezeMode, freezeReason); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(freezeCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// freezeCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// freezeCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	freezeCmd.Flags().StringVar(&freezeMode, "mode", chaincode.FreezeFull, "movements stopped: debit, credit or full")
+	freezeCmd.Flags().StringVar(&freezeReason, "reason", "", "reason code recorded with the freeze")
+	freezeCmd.MarkFlagRequired("reason")
+}
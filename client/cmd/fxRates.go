@@ -0,0 +1,67 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// fxRatesCmd represents the fx rates command
+var fxRatesCmd = &cobra.Command{
+	Use:   "rates",
+	Args:  cobra.NoArgs,
+	Short: "Lists the exchange rates stored on the ledger",
+	Long: `Lists the exchange rates stored on the ledger,
+			with the org that set each rate and when it was set.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Evaluate Transaction: GetFXRates, function returns every exchange rate on the ledger")
+		rates, err := contract.Rates(ctx)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		rows := [][]string{{"BASE", "QUOTE", "RATE", "SET BY", "UPDATED AT"}}
+		for _, rate := range rates {
+			rows = append(rows, []string{rate.Base, rate.Quote, rate.Rate, rate.SetBy, rate.UpdatedAt.Format(time.RFC3339)})
+		}
+		printResult(rates, rows)
+	},
+}
+
+func init() {
+	fxCmd.AddCommand(fxRatesCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// fxRatesCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// fxRatesCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fxRateSetters []string
+	fxMaxRateAge  time.Duration
+)
+
+// fxSetConfigCmd represents the fx set-config command
+var fxSetConfigCmd = &cobra.Command{
+	Use:   "set-config",
+	Args:  cobra.NoArgs,
+	Short: "Sets which orgs can set exchange rates and how long a rate stays usable",
+	Long: `Sets which orgs can set exchange rates and how long a rate stays usable.
+			fx-transfer fails when the rate it needs is older than --max-age.
+			Only the current rate setter orgs can change the configuration.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: SetFXConfig, function stores the FX configuration on the ledger")
+		if err := contract.SetFXConfig(ctx, fxRateSetters, fxMaxRateAge); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+	},
+}
+
+func init() {
+	fxCmd.AddCommand(fxSetConfigCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// fxSetConfigCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// fxSetConfigCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	fxSetConfigCmd.Flags().StringSliceVar(&fxRateSetters, "rate-setters", []string{"Org1MSP"}, "MSP IDs of the orgs allowed to set exchange rates")
+	fxSetConfigCmd.Flags().DurationVar(&fxMaxRateAge, "max-age", time.Hour, "maximum age of a rate used by fx-transfer")
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// fxSetRateCmd represents the fx set-rate command
+var fxSetRateCmd = &cobra.Command{
+	Use:   "set-rate <base> <quote> <rate>",
+	Args:  cobra.ExactArgs(3),
+	Short: "Sets the rate at which one unit of a currency converts into another",
+	Long: `Sets the rate at which one unit of a currency converts into another.
+			Receives base currency, quote currency and rate, e.g. "set-rate USD EUR 0.92".
+			Only the rate setter orgs of the FX configuration can set rates.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		base := args[0]
+		quote := args[1]
+		rate := args[2]
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: SetFXRate, function stores an exchange rate on the ledger")
+		if err := contract.SetRate(ctx, base, quote, rate); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+	},
+}
+
+func init() {
+	fxCmd.AddCommand(fxSetRateCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// fxSetRateCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// fxSetRateCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+var fxTransferMemo string
+
+// fxTransferCmd represents the fx-transfer command
+var fxTransferCmd = &cobra.Command{
+	Use:   "fx-transfer <source-id> <destination-id> <amount>",
+	Args:  cobra.ExactArgs(3),
+	Short: "Transfers the given amount to an account holding another currency",
+	Long: `Transfers the given amount to an account holding another currency.
+			Receives source, destination and amount in the currency of the source account,
+			and credits the destination with the amount converted at the rate stored on the ledger.
+			The fee of the fee schedule is charged in the currency of the source account.
+			The transfer is recorded with the memo given by --memo, and its ID is printed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		source := args[0]
+		dest := args[1]
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		// The amount is written in the currency of the source account.
+		sourceAcc, err := contract.Read(ctx, source)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		amount, err := chaincode.ParseAmount(args[2], sourceAcc.Currency)
+		if err != nil {
+			exitErr(exitUsage, "Invalid amount", err)
+		}
+		log.Println("--> Submit Transaction: TransferWithConversion, function transfers funds converting them to the destination currency")
+		conversion, err := contract.TransferWithConversion(ctx, source, dest, amount, fxTransferMemo)
+		if err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+		printResult(conversion, [][]string{
+			{"TRANSFER", "FROM", "AMOUNT", "FEE", "CURRENCY", "TO", "TO AMOUNT", "TO CURRENCY", "RATE", "RATE SET AT"},
+			{
+				conversion.TxID,
+				conversion.FromID,
+				conversion.FromAmount.FormatDecimal(conversion.FromCurrency),
+				conversion.Fee.FormatDecimal(conversion.FromCurrency),
+				conversion.FromCurrency,
+				conversion.ToID,
+				conversion.ToAmount.FormatDecimal(conversion.ToCurrency),
+				conversion.ToCurrency,
+				conversion.Rate,
+				conversion.RateUpdatedAt.Format(time.RFC3339),
+			},
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fxTransferCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// fxTransferCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// fxTransferCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	fxTransferCmd.Flags().StringVar(&fxTransferMemo, "memo", "", "memo recorded with the transfer")
+}
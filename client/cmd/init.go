@@ -16,30 +16,93 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"log"
 
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
 	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
 	"github.com/spf13/cobra"
 )
 
+var (
+	initFile  string
+	initForce bool
+)
+
+// seedFileAccount is an account of a seed file, whose balance is a decimal string such as "100.50".
+type seedFileAccount struct {
+	ID       string `json:"ID"`
+	Balance  string `json:"Balance"`
+	Currency string `json:"Currency"`
+	Bank     string `json:"Bank"`
+	Type     string `json:"Type"`
+}
+
 // initCmd represents the init command
 var initCmd = &cobra.Command{
 	Use:   "init",
+	Args:  cobra.NoArgs,
 	Short: "Populates the blockchain with some accounts",
 	Long: `Populates the blockchain, submit an InitLedger transaction 
-			that creates the initial set of accounts.`,
+			that creates the initial set of accounts.
+			The accounts are read from the JSON array given by --file, e.g.
+			[{"ID": "account1", "Balance": "100.50", "Currency": "USD", "Bank": "BCC", "Type": "savings"}],
+			whose Type is optional, or a default set is used. Only admins can run it, and it fails if any of
+			the accounts already exists unless --force is given to reset them.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		contract, err := client.NewHyperPayContract()
+		var seed []chaincode.SeedAccount
+		if initFile != "" {
+			var err error
+			seed, err = readSeedFile(initFile)
+			if err != nil {
+				exitErr(exitFailure, "Failed to read seed file", err)
+			}
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
 		if err != nil {
-			log.Fatalf("Failed to create contract client: %v", err)
+			exitErr(exitUnavailable, "Failed to create contract client", err)
 		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
 		log.Println("--> Submit Transaction: InitLedger, function creates the initial set of accounts on the ledger")
-		if err := contract.Init(); err != nil {
-			log.Fatalf("Failed to submit transaction: %v", err)
+		if err := contract.Init(ctx, seed, initForce); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
 		}
 	},
 }
 
+// readSeedFile reads the seed accounts of the given JSON file.
+func readSeedFile(path string) ([]chaincode.SeedAccount, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []seedFileAccount
+	err = json.Unmarshal(data, &accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := make([]chaincode.SeedAccount, len(accounts))
+	for i, account := range accounts {
+		balance, err := chaincode.ParseAmount(account.Balance, account.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %v", account.ID, err)
+		}
+		seed[i] = chaincode.SeedAccount{
+			ID:       account.ID,
+			Balance:  balance,
+			Currency: account.Currency,
+			Bank:     account.Bank,
+			Type:     account.Type,
+		}
+	}
+	return seed, nil
+}
+
 func init() {
 	rootCmd.AddCommand(initCmd)
 
@@ -52,4 +115,6 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// initCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	initCmd.Flags().StringVar(&initFile, "file", "", "JSON file with the accounts to create")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "reset the accounts that already exist")
 }
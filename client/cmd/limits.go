@@ -0,0 +1,42 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// limitsCmd represents the limits command
+var limitsCmd = &cobra.Command{
+	Use:   "limits",
+	Short: "Manages the transfer limits of the accounts",
+	Long: `Manages the transfer limits of the accounts: the maximum amount of a single transfer,
+			and the maximum total an account can send in a day and in a month.`,
+}
+
+func init() {
+	rootCmd.AddCommand(limitsCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// limitsCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// limitsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// limitsGetCmd represents the limits get command
+var limitsGetCmd = &cobra.Command{
+	Use:   "get <account-id>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Shows the transfer limits of the given account",
+	Long: `Shows the transfer limits of the given account, with what it has sent in the
+			current day and month, UTC. A limit of 0 is no limit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		// The limits are shown in the currency of the account.
+		account, err := contract.Read(ctx, id)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		log.Println("--> Evaluate Transaction: GetAccountLimits, function returns the limits of an account")
+		limits, err := contract.Limits(ctx, id)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		usage := limits.Usage
+		if usage == nil {
+			usage = &chaincode.LimitUsage{}
+		}
+		currency := account.Currency
+		printResult(limits, [][]string{
+			{"LIMIT", "AMOUNT", "USED", "CURRENCY"},
+			{"per transfer", limits.MaxTransfer.FormatDecimal(currency), "", currency},
+			{"daily", limits.Daily.FormatDecimal(currency), usage.DailyTotal.FormatDecimal(currency), currency},
+			{"monthly", limits.Monthly.FormatDecimal(currency), usage.MonthlyTotal.FormatDecimal(currency), currency},
+		})
+	},
+}
+
+func init() {
+	limitsCmd.AddCommand(limitsGetCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// limitsGetCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// limitsGetCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	limitsMaxTransfer string
+	limitsDaily       string
+	limitsMonthly     string
+)
+
+// limitsSetCmd represents the limits set command
+var limitsSetCmd = &cobra.Command{
+	Use:   "set <account-id>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Sets the transfer limits of the given account",
+	Long: `Sets the transfer limits of the given account, written in its currency.
+			Only the limits given by --max-transfer, --daily and --monthly change,
+			and a limit of 0 removes it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		flags := cmd.Flags()
+		if !flags.Changed("max-transfer") && !flags.Changed("daily") && !flags.Changed("monthly") {
+			exitf(exitUsage, "Give at least one of --max-transfer, --daily and --monthly")
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		// The limits are written in the currency of the account, and those not given are kept.
+		account, err := contract.Read(ctx, id)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		limits, err := contract.Limits(ctx, id)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		for _, limit := range []struct {
+			flag   string
+			value  string
+			amount *chaincode.Amount
+		}{
+			{"max-transfer", limitsMaxTransfer, &limits.MaxTransfer},
+			{"daily", limitsDaily, &limits.Daily},
+			{"monthly", limitsMonthly, &limits.Monthly},
+		} {
+			if !flags.Changed(limit.flag) {
+				continue
+			}
+			*limit.amount, err = chaincode.ParseAmount(limit.value, account.Currency)
+			if err != nil {
+				exitErr(exitUsage, "Invalid --"+limit.flag, err)
+			}
+		}
+		log.Println("--> Submit Transaction: SetAccountLimits, function sets the limits of an account")
+		if err := contract.SetLimits(ctx, id, limits.MaxTransfer, limits.Daily, limits.Monthly); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+	},
+}
+
+func init() {
+	limitsCmd.AddCommand(limitsSetCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// limitsSetCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// limitsSetCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	limitsSetCmd.Flags().StringVar(&limitsMaxTransfer, "max-transfer", "", "maximum amount of a single transfer, 0 for no limit")
+	limitsSetCmd.Flags().StringVar(&limitsDaily, "daily", "", "maximum total sent in a day, 0 for no limit")
+	limitsSetCmd.Flags().StringVar(&limitsMonthly, "monthly", "", "maximum total sent in a month, 0 for no limit")
+}
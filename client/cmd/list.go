@@ -0,0 +1,109 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listBank     string
+	listOwner    string
+	listCurrency string
+	listMin      string
+	listMax      string
+	listPageSize int32
+	listBookmark string
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.NoArgs,
+	Short: "Lists the accounts, optionally filtered by bank, owner, currency and balance",
+	Long: `Lists a page of accounts, optionally filtered by bank, owner, currency and balance.
+			The balance bounds are written in the currency given by --currency, USD by default,
+			and only accounts holding that currency are listed when they are set.
+			When there are more accounts the bookmark of the next page is printed to stderr, and
+			included in the JSON and YAML output; pass it with --bookmark to get that page.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		verifyOutputFormat(outputFormats...)
+		filter := chaincode.AccountFilter{Bank: listBank, Owner: listOwner, Currency: listCurrency}
+		if (listMin != "" || listMax != "") && filter.Currency == "" {
+			filter.Currency = chaincode.DefaultCurrency
+		}
+		var err error
+		if listMin != "" {
+			filter.MinBalance, err = chaincode.ParseAmount(listMin, filter.Currency)
+			if err != nil {
+				exitErr(exitUsage, "Invalid minimum balance", err)
+			}
+		}
+		if listMax != "" {
+			filter.MaxBalance, err = chaincode.ParseAmount(listMax, filter.Currency)
+			if err != nil {
+				exitErr(exitUsage, "Invalid maximum balance", err)
+			}
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		var page *chaincode.AccountPage
+		if filter == (chaincode.AccountFilter{}) {
+			log.Println("--> Evaluate Transaction: ListAccounts, function returns a page of accounts")
+			page, err = contract.ListPage(ctx, listPageSize, listBookmark)
+		} else {
+			log.Println("--> Evaluate Transaction: QueryAccounts, function returns a page of the accounts matching a filter")
+			page, err = contract.QueryPage(ctx, filter, listPageSize, listBookmark)
+		}
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		printResult(page, accountRows(page.Accounts...))
+		if page.Bookmark != "" {
+			log.Printf("Next page: --bookmark %q", page.Bookmark)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// listCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// listCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	listCmd.Flags().StringVar(&listBank, "bank", "", "only list the accounts of this bank")
+	listCmd.Flags().StringVar(&listOwner, "owner", "", "only list the accounts owned by this client ID")
+	listCmd.Flags().StringVar(&listCurrency, "currency", "", "only list the accounts holding this currency")
+	listCmd.Flags().StringVar(&listMin, "min", "", "only list the accounts with at least this balance")
+	listCmd.Flags().StringVar(&listMax, "max", "", "only list the accounts with at most this balance")
+	listCmd.Flags().Int32Var(&listPageSize, "page-size", 50, "maximum number of accounts to list")
+	listCmd.Flags().StringVar(&listBookmark, "bookmark", "", "bookmark of the page to list, printed by the previous page")
+}
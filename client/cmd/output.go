@@ -0,0 +1,210 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// Formats of the results printed by the commands, selected with --output.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputCSV   = "csv"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML, outputCSV}
+
+// Exit codes of the CLI, by class of error.
+const (
+	// exitFailure is any error not in another class.
+	exitFailure = 1
+	// exitUsage is an invalid command line: unknown command or flag, or invalid argument.
+	exitUsage = 2
+	// exitNotFound is a missing account, transfer or wallet identity.
+	exitNotFound = 3
+	// exitDenied is a transaction the identity is not allowed to run.
+	exitDenied = 4
+	// exitRejected is a transaction the contract rejected for another reason.
+	exitRejected = 5
+	// exitUnavailable is a network or backend that could not be reached in time.
+	exitUnavailable = 6
+	// exitAlreadyExists is an account that already exists.
+	exitAlreadyExists = 7
+	// exitInsufficientFunds is a transfer from an account without enough balance.
+	exitInsufficientFunds = 8
+	// exitEndorsement is a transaction that did not get the endorsements it needs.
+	exitEndorsement = 9
+	// exitFrozen is a transaction stopped by the freeze of an account.
+	exitFrozen = 10
+	// exitLimitExceeded is a transfer above a limit of its source account.
+	exitLimitExceeded = 11
+)
+
+// outputFormat returns the format selected with --output.
+func outputFormat() string {
+	return strings.ToLower(viper.GetString("output"))
+}
+
+// verifyOutputFormat checks that the --output format is one of the given ones.
+func verifyOutputFormat(formats ...string) {
+	format := outputFormat()
+	for _, f := range formats {
+		if f == format {
+			return
+		}
+	}
+	last := len(formats) - 1
+	exitf(exitUsage, "Invalid --output %q, it must be %s or %s", format, strings.Join(formats[:last], ", "), formats[last])
+}
+
+// printResult prints the result of a command to stdout, in the --output format.
+func printResult(value interface{}, rows [][]string) {
+	if err := writeResult(os.Stdout, outputFormat(), value, rows); err != nil {
+		exitErr(exitFailure, "Failed to print the result", err)
+	}
+}
+
+// writeResult writes a result in the given format. JSON and YAML encode the value, while table and
+// CSV write the rows, the first one being the header.
+func writeResult(out io.Writer, format string, value interface{}, rows [][]string) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(value)
+	case outputYAML:
+		return writeYAML(out, value)
+	case outputCSV:
+		w := csv.NewWriter(out)
+		w.WriteAll(rows)
+		return w.Error()
+	default:
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+}
+
+// accountRows returns the table of the given accounts, with their balance in decimal notation,
+// their status and their freeze, if any.
+func accountRows(accounts ...*chaincode.Account) [][]string {
+	rows := [][]string{{"ID", "BANK", "TYPE", "CURRENCY", "BALANCE", "STATUS", "OWNER", "FROZEN"}}
+	for _, acc := range accounts {
+		frozen := ""
+		if acc.Freeze != nil {
+			frozen = acc.Freeze.Mode + " (" + acc.Freeze.Reason + ")"
+		}
+		rows = append(rows, []string{acc.ID, acc.Bank, acc.Type, acc.Currency, acc.Balance.FormatDecimal(acc.Currency), acc.Status, acc.Owner, frozen})
+	}
+	return rows
+}
+
+// writeYAML writes the value as YAML, with the same field names as its JSON encoding.
+func writeYAML(out io.Writer, value interface{}) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(valueJSON, &generic); err != nil {
+		return err
+	}
+	valueYAML, err := yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(valueYAML)
+	return err
+}
+
+// exitf logs the message to stderr and exits with the given code.
+func exitf(code int, format string, v ...interface{}) {
+	log.Printf(format, v...)
+	os.Exit(code)
+}
+
+// exitErr logs the message followed by the error, and a hint on how to solve it if there is one,
+// and exits with the code of the class of the error, or with the given code when the error is not
+// in a known class.
+func exitErr(code int, msg string, err error) {
+	log.Printf("%s: %v", msg, err)
+	if hint := errorHint(err); hint != "" {
+		log.Println(hint)
+	}
+	os.Exit(errorExitCode(err, code))
+}
+
+// errorExitCode returns the exit code of the class of the error, or the given code when the error
+// is not in a known class.
+func errorExitCode(err error, code int) int {
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, client.ErrUnauthorized):
+		return exitDenied
+	case errors.Is(err, client.ErrAlreadyExists):
+		return exitAlreadyExists
+	case errors.Is(err, client.ErrInsufficientFunds):
+		return exitInsufficientFunds
+	case errors.Is(err, client.ErrEndorsement):
+		return exitEndorsement
+	case errors.Is(err, client.ErrAccountFrozen):
+		return exitFrozen
+	case errors.Is(err, client.ErrLimitExceeded):
+		return exitLimitExceeded
+	case errors.Is(err, context.DeadlineExceeded), isConnectionError(err):
+		return exitUnavailable
+	}
+	return code
+}
+
+// errorHint returns a hint on how to solve the error, or an empty string.
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, client.ErrUnauthorized):
+		return fmt.Sprintf("The identity %s is not allowed to do this; choose another one with --identity or \"wallet use\".", viper.GetString("identity"))
+	case errors.Is(err, client.ErrEndorsement):
+		return "The peers did not endorse the transaction as the endorsement policy requires; check that they are running and retry."
+	case errors.Is(err, context.DeadlineExceeded):
+		return "The network did not answer in time."
+	}
+	return ""
+}
+
+// isConnectionError reports whether the error comes from a network that could not be reached.
+func isConnectionError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "connection refused") || strings.Contains(msg, "failed to connect")
+}
@@ -16,7 +16,6 @@ limitations under the License.
 package cmd
 
 import (
-	"encoding/json"
 	"log"
 
 	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
@@ -25,26 +24,27 @@ import (
 
 // readCmd represents the read command
 var readCmd = &cobra.Command{
-	Use:   "read",
+	Use:   "read <account-id>",
+	Args:  cobra.ExactArgs(1),
 	Short: "Reads the details of the given account",
 	Long: `Reads the details of the given account.
 			Receives an id transaction and reads its value`,
 	Run: func(cmd *cobra.Command, args []string) {
 		id := args[0]
-		contract, err := client.NewHyperPayContract()
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
 		if err != nil {
-			log.Fatalf("Failed to create contract client: %v", err)
+			exitErr(exitUnavailable, "Failed to create contract client", err)
 		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
 		log.Println("--> Evaluate Transaction: ReadAccount, function reads the value of an account")
-		acc, err := contract.Read(id)
+		acc, err := contract.Read(ctx, id)
 		if err != nil {
-			log.Fatalf("Failed to evaluate transaction: %v", err)
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
 		}
-		accBytes, err := json.Marshal(*acc)
-		if err != nil {
-			panic(err)
-		}
-		log.Println(string(accBytes))
+		printResult(acc, accountRows(acc))
 	},
 }
 
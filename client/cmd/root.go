@@ -16,10 +16,15 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
 
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
@@ -39,10 +44,11 @@ smart contract, which is published on Hyperledger Fabric.`,
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The commands exit on their own errors, so the errors left are those of the command line,
+// which cobra already printed to stderr.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 }
 
@@ -55,6 +61,25 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.client.yaml)")
 
+	// Connection settings, also read from the config file and from HYPERPAY_* environment
+	// variables, e.g. HYPERPAY_MSP_DIR for --msp-dir.
+	defaults := client.DefaultOptions()
+	rootCmd.PersistentFlags().String("channel", defaults.ChannelID, "channel the contract is deployed on")
+	rootCmd.PersistentFlags().String("chaincode", defaults.ChaincodeName, "name the contract was deployed with")
+	rootCmd.PersistentFlags().String("identity", defaults.Identity, "label of the wallet identity that signs the transactions")
+	rootCmd.PersistentFlags().String("wallet", defaults.WalletPath, "directory of the wallet")
+	rootCmd.PersistentFlags().String("msp-dir", defaults.MSPDir, "MSP directory the identity is imported from when it is not in the wallet")
+	rootCmd.PersistentFlags().String("mspid", defaults.MSPID, "MSP ID of the identity imported from --msp-dir")
+	rootCmd.PersistentFlags().String("ccp", defaults.CCPPath, "connection profile describing the network")
+	rootCmd.PersistentFlags().String("backend", defaults.Backend, "where the transactions run: gateway (a Fabric network) or sim (an in-process simulator)")
+	rootCmd.PersistentFlags().String("sim-ledger", "sim-ledger.json", "file the sim backend keeps its ledger in, empty to keep it in memory")
+	rootCmd.PersistentFlags().StringP("output", "o", outputTable, "format of the results printed to stdout: table, json, yaml or csv")
+	for _, name := range []string{"channel", "chaincode", "identity", "wallet", "msp-dir", "mspid", "ccp", "backend", "sim-ledger", "output"} {
+		if err := viper.BindPFlag(name, rootCmd.PersistentFlags().Lookup(name)); err != nil {
+			panic(err)
+		}
+	}
+
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
@@ -69,8 +94,8 @@ func initConfig() {
 		// Find home directory.
 		home, err := homedir.Dir()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitFailure)
 		}
 
 		// Search config in home directory with name ".client" (without extension).
@@ -78,10 +103,68 @@ func initConfig() {
 		viper.SetConfigName(".client")
 	}
 
+	viper.SetEnvPrefix("hyperpay")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv() // read in environment variables that match
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	}
+}
+
+// contractOptions returns the connection settings given by the flags, the environment and the config file.
+func contractOptions() client.Options {
+	return client.Options{
+		ChannelID:     viper.GetString("channel"),
+		ChaincodeName: viper.GetString("chaincode"),
+		Identity:      viper.GetString("identity"),
+		WalletPath:    viper.GetString("wallet"),
+		MSPDir:        viper.GetString("msp-dir"),
+		MSPID:         viper.GetString("mspid"),
+		CCPPath:       viper.GetString("ccp"),
+		Backend:       viper.GetString("backend"),
+		SimLedgerPath: viper.GetString("sim-ledger"),
+	}
+}
+
+// saveConfigValue sets the given key in the config file, creating $HOME/.client.yaml when no config
+// file is in use, and returns the path of the file. Only the key is written: settings taken from
+// flags and environment variables are left out of the file.
+func saveConfigValue(key, value string) (string, error) {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, ".client.yaml")
 	}
+
+	config := viper.New()
+	config.SetConfigFile(path)
+	if _, err := os.Stat(path); err == nil {
+		if err := config.ReadInConfig(); err != nil {
+			return "", err
+		}
+	}
+	config.Set(key, value)
+	return path, config.WriteConfigAs(path)
+}
+
+// commandContext returns the context of the contract calls made by a command, which is cancelled
+// when the user interrupts the command.
+func commandContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(interrupt)
+	}()
+	return ctx, cancel
 }
@@ -0,0 +1,68 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// setStatusCmd represents the set-status command
+var setStatusCmd = &cobra.Command{
+	Use:   "set-status <account-id> <status>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Changes the status of the given account",
+	Long: `Changes the status of the given account to pending, active or dormant.
+			Pending accounts can neither send nor receive money, and dormant accounts can only
+			receive it. Accounts are closed with the delete command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		status := args[1]
+		switch status {
+		case chaincode.StatusPending, chaincode.StatusActive, chaincode.StatusDormant:
+		default:
+			exitf(exitUsage, "Invalid status %q, it must be pending, active or dormant", status)
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: SetAccountStatus, function changes the status of an account")
+		if err := contract.SetStatus(ctx, id, status); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(setStatusCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// setStatusCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// setStatusCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,240 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// statementDateLayout is the layout of the dates accepted by --from and --to, besides RFC 3339.
+	statementDateLayout = "2006-01-02"
+	// outputText is the plain text report, an output format only supported by statements.
+	outputText = "text"
+)
+
+var (
+	statementFrom   string
+	statementTo     string
+	statementFormat string
+)
+
+// statementCmd represents the statement command
+var statementCmd = &cobra.Command{
+	Use:   "statement <account-id>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Prints the statement of the given account",
+	Long: `Prints the statement of the given account: its opening balance, every credit and debit
+			with the resulting balance, and its closing balance.
+			The period is set with --from and --to, as dates (2006-01-02) or RFC 3339 timestamps;
+			a --to date includes that whole day. Without them the statement covers the whole
+			history of the account.
+			Besides the formats of --output, it can be printed with --output text as a plain text
+			report to send to customers.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		from, err := parseStatementBound(statementFrom, false)
+		if err != nil {
+			exitErr(exitUsage, "Invalid --from", err)
+		}
+		to, err := parseStatementBound(statementTo, true)
+		if err != nil {
+			exitErr(exitUsage, "Invalid --to", err)
+		}
+		format := outputFormat()
+		if cmd.Flags().Changed("format") {
+			format = statementFormat
+		}
+		var write func(io.Writer, *chaincode.Statement) error
+		switch format {
+		case outputTable:
+			write = writeStatementTable
+		case outputCSV:
+			write = writeStatementCSV
+		case outputJSON, outputYAML:
+			write = func(out io.Writer, statement *chaincode.Statement) error {
+				return writeResult(out, format, statement, nil)
+			}
+		case outputText:
+			write = writeStatementText
+		default:
+			exitf(exitUsage, "Invalid --output %q, it must be table, json, yaml, csv or text", format)
+		}
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Evaluate Transaction: GetStatement, function gets the statement of the given account")
+		statement, err := contract.Statement(ctx, id, from, to)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		if err := write(os.Stdout, statement); err != nil {
+			exitErr(exitFailure, "Failed to write the statement", err)
+		}
+	},
+}
+
+// parseStatementBound parses a --from or --to value. A date is the start of that day in UTC, or
+// the start of the next day for the end of the period, so that the day is included.
+func parseStatementBound(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse(statementDateLayout, value); err == nil {
+		if end {
+			date = date.AddDate(0, 0, 1)
+		}
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// statementDescription describes an entry for a reader of the statement.
+func statementDescription(entry *chaincode.StatementEntry) string {
+	switch entry.Kind {
+	case chaincode.EntryOpened:
+		return "Account opened"
+	case chaincode.EntryDeleted:
+		return "Account closed"
+	case chaincode.EntryTransfer:
+		if entry.Amount < 0 {
+			return "Transfer to " + entry.Counterparty
+		}
+		return "Transfer from " + entry.Counterparty
+	case chaincode.EntryBatch:
+		return "Batch of transfers"
+	case chaincode.EntryFee:
+		return "Fee of a transfer from " + entry.Counterparty
+	case chaincode.EntryEscrow:
+		return "Escrow for " + entry.Counterparty
+	case chaincode.EntryEscrowRefund:
+		return "Refund of the escrow for " + entry.Counterparty
+	default:
+		return "Balance adjustment"
+	}
+}
+
+// statementPeriod describes the period of the statement, whose end is excluded.
+func statementPeriod(statement *chaincode.Statement) string {
+	from, to := "the opening of the account", "now"
+	if !statement.From.IsZero() {
+		from = statement.From.Format(time.RFC3339)
+	}
+	if !statement.To.IsZero() {
+		to = statement.To.Format(time.RFC3339)
+	}
+	return "from " + from + " until " + to
+}
+
+func writeStatementTable(out io.Writer, statement *chaincode.Statement) error {
+	currency := statement.Currency
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Date\tTransaction\tDescription\tAmount\tBalance\tMemo\n")
+	fmt.Fprintf(w, "\t\tOpening balance\t\t%s\t\n", statement.OpeningBalance.Format(currency))
+	for _, entry := range statement.Entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Timestamp.Format(time.RFC3339),
+			entry.TxID,
+			statementDescription(entry),
+			formatSignedAmount(entry.Amount, currency),
+			entry.Balance.Format(currency),
+			entry.Memo,
+		)
+	}
+	fmt.Fprintf(w, "\t\tClosing balance\t\t%s\t\n", statement.ClosingBalance.Format(currency))
+	return w.Flush()
+}
+
+func writeStatementCSV(out io.Writer, statement *chaincode.Statement) error {
+	currency := statement.Currency
+	w := csv.NewWriter(out)
+	w.Write([]string{"date", "transaction", "kind", "counterparty", "memo", "amount", "balance", "currency"})
+	for _, entry := range statement.Entries {
+		w.Write([]string{
+			entry.Timestamp.Format(time.RFC3339),
+			entry.TxID,
+			entry.Kind,
+			entry.Counterparty,
+			entry.Memo,
+			entry.Amount.FormatDecimal(currency),
+			entry.Balance.FormatDecimal(currency),
+			currency,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeStatementText(out io.Writer, statement *chaincode.Statement) error {
+	currency := statement.Currency
+	fmt.Fprintf(out, "Statement of account %s (%s)\n", statement.AccountID, currency)
+	fmt.Fprintf(out, "Period: %s\n\n", statementPeriod(statement))
+	fmt.Fprintf(out, "Opening balance: %s\n\n", statement.OpeningBalance.Format(currency))
+	if len(statement.Entries) == 0 {
+		fmt.Fprintln(out, "No transactions in this period.")
+	}
+	for _, entry := range statement.Entries {
+		fmt.Fprintf(out, "%s  %s\n", entry.Timestamp.Format(statementDateLayout), statementDescription(entry))
+		if entry.Memo != "" {
+			fmt.Fprintf(out, "            %s\n", entry.Memo)
+		}
+		fmt.Fprintf(out, "            %s, balance %s\n", formatSignedAmount(entry.Amount, currency), entry.Balance.Format(currency))
+	}
+	fmt.Fprintf(out, "\nTotal credits:   %s\n", statement.TotalCredits.Format(currency))
+	fmt.Fprintf(out, "Total debits:    %s\n", statement.TotalDebits.Format(currency))
+	_, err := fmt.Fprintf(out, "Closing balance: %s\n", statement.ClosingBalance.Format(currency))
+	return err
+}
+
+// formatSignedAmount formats the amount with its sign, so that credits stand out from debits.
+func formatSignedAmount(amount chaincode.Amount, currency string) string {
+	if amount > 0 {
+		return "+" + amount.Format(currency)
+	}
+	return amount.Format(currency)
+}
+
+func init() {
+	rootCmd.AddCommand(statementCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// statementCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// statementCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	statementCmd.Flags().StringVar(&statementFrom, "from", "", "start of the period, as a date or RFC 3339 timestamp")
+	statementCmd.Flags().StringVar(&statementTo, "to", "", "end of the period, as a date (included) or RFC 3339 timestamp (excluded)")
+	statementCmd.Flags().StringVarP(&statementFormat, "format", "f", outputTable, "output format: table, json, yaml, csv or text")
+	statementCmd.Flags().MarkDeprecated("format", "use --output instead")
+}
@@ -16,35 +16,71 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
 	"log"
 
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
 	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
 	"github.com/spf13/cobra"
 )
 
+var (
+	transferMemo  string
+	transferQuote bool
+)
+
 // transferCmd represents the transfer command
 var transferCmd = &cobra.Command{
-	Use:   "transfer",
+	Use:   "transfer <source-id> <destination-id> <amount>",
+	Args:  cobra.ExactArgs(3),
 	Short: "Transfers the given amount from the given source account to the given destination account",
 	Long: `"Transfers the given amount from the given source account to the given destination account
-			Receives source, destination and amount, and executes the transaction.`,
+			Receives source, destination and amount, and executes the transaction.
+			The transfer is recorded with the memo given by --memo, and its ID is printed.
+			The fee charged on top of the amount is printed with it; --quote prints the fee
+			the transfer would be charged without making it.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		source := args[0]
 		dest := args[1]
-		var amount float32
-		_, err := fmt.Sscan(args[2], &amount)
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		// The amount is written in the currency of the source account.
+		sourceAcc, err := contract.Read(ctx, source)
 		if err != nil {
-			panic(err)
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
 		}
-		contract, err := client.NewHyperPayContract()
+		amount, err := chaincode.ParseAmount(args[2], sourceAcc.Currency)
 		if err != nil {
-			log.Fatalf("Failed to create contract client: %v", err)
+			exitErr(exitUsage, "Invalid amount", err)
+		}
+		if transferQuote {
+			log.Println("--> Evaluate Transaction: QuoteTransfer, function returns the fee of a transfer")
+			quote, err := contract.QuoteTransfer(ctx, source, dest, amount)
+			if err != nil {
+				exitErr(exitRejected, "Failed to evaluate transaction", err)
+			}
+			printResult(quote, [][]string{
+				{"FROM", "TO", "AMOUNT", "FEE", "TOTAL", "CURRENCY", "FEE COLLECTOR"},
+				{quote.FromID, quote.ToID, quote.Amount.FormatDecimal(quote.Currency), quote.Fee.FormatDecimal(quote.Currency),
+					quote.Total.FormatDecimal(quote.Currency), quote.Currency, quote.FeeCollector},
+			})
+			return
 		}
 		log.Println("--> Submit Transaction: Transfer, function transfers funds from one account to another")
-		if err := contract.Transfer(source, dest, amount); err != nil {
-			log.Fatalf("Failed to submit transaction: %v", err)
+		transfer, err := contract.Transfer(ctx, source, dest, amount, transferMemo)
+		if err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
 		}
+		printResult(transfer, [][]string{
+			{"TRANSFER", "FROM", "TO", "AMOUNT", "FEE", "CURRENCY", "MEMO"},
+			{transfer.ID, transfer.FromID, transfer.ToID, transfer.Amount.FormatDecimal(transfer.Currency),
+				transfer.Fee.FormatDecimal(transfer.Currency), transfer.Currency, transfer.Memo},
+		})
 	},
 }
 
@@ -60,4 +96,6 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// transferCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	transferCmd.Flags().StringVar(&transferMemo, "memo", "", "memo recorded with the transfer")
+	transferCmd.Flags().BoolVar(&transferQuote, "quote", false, "print the fee of the transfer without making it")
 }
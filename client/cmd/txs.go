@@ -16,41 +16,98 @@ limitations under the License.
 package cmd
 
 import (
-	"encoding/json"
 	"log"
+	"strconv"
+	"time"
 
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
 	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
 	"github.com/spf13/cobra"
 )
 
+var (
+	txsHistory  bool
+	txsPageSize int32
+)
+
 // txsCmd represents the txs command
 var txsCmd = &cobra.Command{
-	Use:   "txs",
+	Use:   "txs <account-id>",
+	Args:  cobra.ExactArgs(1),
 	Short: "Returns all transactions involving given account",
 	Long: `Returns all transactions involving given account
-			Receives an account id and gives the transaction history of the given account.`,
+			Receives an account id and prints the statement of its transfers, in the order they
+			happened, with their counterparty and the amount credited or debited to the account.
+			Debits are negative amounts. With --history it prints every state the account has
+			gone through instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		id := args[0]
-		contract, err := client.NewHyperPayContract()
-		if err != nil {
-			log.Fatalf("Failed to create contract client: %v", err)
-		}
-		log.Println("--> Evaluate Transaction: GetAllTxs, function gets transaction history of the given account")
-		records, err := contract.Txs(id)
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
 		if err != nil {
-			log.Fatalf("Failed to evaluate transaction: %v", err)
+			exitErr(exitUnavailable, "Failed to create contract client", err)
 		}
-		log.Println("History of " + id + ": ")
-		for i := 0; i < len(records); i++ {
-			recBytes, err := json.Marshal(records[i])
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		if txsHistory {
+			log.Println("--> Evaluate Transaction: GetAllTxs, function gets transaction history of the given account")
+			records, err := contract.Txs(ctx, id)
 			if err != nil {
-				panic(err)
+				exitErr(exitRejected, "Failed to evaluate transaction", err)
+			}
+			rows := [][]string{{"DATE", "TRANSACTION", "DELETED", "BALANCE", "CURRENCY"}}
+			for _, record := range records {
+				row := []string{record.Timestamp.Format(time.RFC3339), record.TxId, strconv.FormatBool(record.IsDelete), "", ""}
+				if !record.IsDelete {
+					row[3], row[4] = record.Record.Balance.FormatDecimal(record.Record.Currency), record.Record.Currency
+				}
+				rows = append(rows, row)
 			}
-			log.Println(string(recBytes))
+			printResult(records, rows)
+			return
 		}
+
+		log.Println("--> Evaluate Transaction: ListTransfersForAccount, function gets the transfers of the given account")
+		transfers := []*chaincode.TransferRecord{}
+		rows := [][]string{{"DATE", "TRANSFER", "COUNTERPARTY", "AMOUNT", "CURRENCY", "MEMO"}}
+		it := contract.Transfers(ctx, id, txsPageSize)
+		for it.Next() {
+			transfers = append(transfers, it.Transfer())
+			rows = append(rows, statementRows(id, it.Transfer())...)
+		}
+		if err := it.Err(); err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		printResult(transfers, rows)
 	},
 }
 
+// statementRows returns the statement rows of a transfer for the given account: the debit, as a
+// negative amount, and its fee when it is the source, and the credit when it is the destination or
+// the collector of the fee.
+func statementRows(accountID string, transfer *chaincode.TransferRecord) [][]string {
+	date := transfer.Timestamp.Format(time.RFC3339)
+	var rows [][]string
+	if transfer.FromID == accountID {
+		rows = append(rows, []string{date, transfer.ID, "to " + transfer.ToID,
+			(-transfer.Amount).FormatDecimal(transfer.Currency), transfer.Currency, transfer.Memo})
+		if transfer.Fee > 0 {
+			rows = append(rows, []string{date, transfer.ID, "fee to " + transfer.FeeCollector,
+				(-transfer.Fee).FormatDecimal(transfer.Currency), transfer.Currency, ""})
+		}
+	}
+	if transfer.ToID == accountID {
+		rows = append(rows, []string{date, transfer.ID, "from " + transfer.FromID,
+			transfer.ToAmount.FormatDecimal(transfer.ToCurrency), transfer.ToCurrency, transfer.Memo})
+	}
+	if transfer.FeeCollector == accountID {
+		rows = append(rows, []string{date, transfer.ID, "fee from " + transfer.FromID,
+			transfer.Fee.FormatDecimal(transfer.Currency), transfer.Currency, ""})
+	}
+	return rows
+}
+
 func init() {
 	rootCmd.AddCommand(txsCmd)
 
@@ -63,4 +120,6 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// txsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	txsCmd.Flags().BoolVar(&txsHistory, "history", false, "print the states of the account instead of its transfers")
+	txsCmd.Flags().Int32Var(&txsPageSize, "page-size", 100, "number of transfers fetched at a time")
 }
@@ -0,0 +1,60 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// unfreezeCmd represents the unfreeze command
+var unfreezeCmd = &cobra.Command{
+	Use:   "unfreeze <account-id>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Lifts the freeze of the given account",
+	Long: `Lifts the freeze of the given account, so that money can move in and out of it again.
+			Only admins and compliance officers can unfreeze accounts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Submit Transaction: UnfreezeAccount, function lifts the freeze of an account")
+		if err := contract.Unfreeze(ctx, id); err != nil {
+			exitErr(exitRejected, "Failed to submit transaction", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unfreezeCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// unfreezeCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// unfreezeCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
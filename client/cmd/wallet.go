@@ -0,0 +1,55 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// walletCmd represents the wallet command
+var walletCmd = &cobra.Command{
+	Use:   "wallet",
+	Short: "Manages the identities of the wallet",
+	Long: `Manages the identities stored in the wallet given by --wallet.
+			The identity that signs the transactions is chosen with --identity, or remembered
+			in the config file with "wallet use".`,
+}
+
+func init() {
+	rootCmd.AddCommand(walletCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// walletCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// walletCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
+
+// openWallet opens the file system wallet given by the --wallet flag.
+func openWallet() *gateway.Wallet {
+	wallet, err := gateway.NewFileSystemWallet(viper.GetString("wallet"))
+	if err != nil {
+		exitErr(exitFailure, "Failed to open wallet", err)
+	}
+	return wallet
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// walletExportCmd represents the wallet export command
+var walletExportCmd = &cobra.Command{
+	Use:   "export <label> <msp-dir>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Exports an identity of the wallet to an MSP directory",
+	Long: `Exports an identity of the wallet to an MSP directory.
+			Receives the label of the identity and the directory, e.g. "export User1@org1.example.com ./user1-msp".
+			The certificate is written to signcerts/cert.pem and the private key to keystore/priv_sk.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		label := args[0]
+		dir := args[1]
+		if err := client.ExportIdentity(openWallet(), label, dir); err != nil {
+			exitErr(exitFailure, "Failed to export identity", err)
+		}
+		log.Printf("Exported identity %s to %s", label, dir)
+	},
+}
+
+func init() {
+	walletCmd.AddCommand(walletExportCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// walletExportCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// walletExportCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// walletImportCmd represents the wallet import command
+var walletImportCmd = &cobra.Command{
+	Use:   "import <label>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Imports an identity from an MSP directory into the wallet",
+	Long: `Imports an identity from an MSP directory into the wallet.
+			Receives the label to store the identity under, e.g. "import Admin@org2.example.com".
+			The certificate is read from the signcerts folder of --msp-dir and its private key
+			from the keystore folder, which may hold several keys. --mspid gives the MSP ID of the identity.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		label := args[0]
+		wallet := openWallet()
+		if wallet.Exists(label) {
+			force, _ := cmd.Flags().GetBool("force")
+			if !force {
+				exitf(exitFailure, "The identity %s is already in the wallet, use --force to replace it", label)
+			}
+		}
+		err := client.ImportIdentity(wallet, label, viper.GetString("msp-dir"), viper.GetString("mspid"))
+		if err != nil {
+			exitErr(exitFailure, "Failed to import identity", err)
+		}
+		log.Printf("Imported identity %s", label)
+	},
+}
+
+func init() {
+	walletCmd.AddCommand(walletImportCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// walletImportCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// walletImportCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	walletImportCmd.Flags().Bool("force", false, "replace the identity if the label is already in the wallet")
+}
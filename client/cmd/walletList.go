@@ -0,0 +1,79 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"strings"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// walletIdentity is an identity listed by the wallet list command.
+type walletIdentity struct {
+	client.IdentityInfo
+	Active bool
+}
+
+// walletListCmd represents the wallet list command
+var walletListCmd = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.NoArgs,
+	Short: "Lists the identities of the wallet",
+	Long: `Lists the identities of the wallet with their MSP ID, common name, organizational units
+			and expiry date. The identity in use is marked with an asterisk.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		verifyOutputFormat(outputFormats...)
+		identities, err := client.ListIdentities(openWallet())
+		if err != nil {
+			exitErr(exitFailure, "Failed to list identities", err)
+		}
+		active := viper.GetString("identity")
+		listed := []walletIdentity{}
+		rows := [][]string{{"ACTIVE", "LABEL", "MSP ID", "COMMON NAME", "OUS", "EXPIRES"}}
+		for _, identity := range identities {
+			listed = append(listed, walletIdentity{IdentityInfo: identity, Active: identity.Label == active})
+			marker := ""
+			if identity.Label == active {
+				marker = "*"
+			}
+			rows = append(rows, []string{
+				marker,
+				identity.Label,
+				identity.MSPID,
+				identity.CommonName,
+				strings.Join(identity.OrganizationalUnits, ","),
+				identity.Expires.Format("2006-01-02"),
+			})
+		}
+		printResult(listed, rows)
+	},
+}
+
+func init() {
+	walletCmd.AddCommand(walletListCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// walletListCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// walletListCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// walletRemoveCmd represents the wallet remove command
+var walletRemoveCmd = &cobra.Command{
+	Use:   "remove <label>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Removes an identity from the wallet",
+	Long: `Removes an identity from the wallet.
+			Receives the label of the identity.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		label := args[0]
+		wallet := openWallet()
+		if !wallet.Exists(label) {
+			exitf(exitNotFound, "The identity %s is not in the wallet", label)
+		}
+		if err := wallet.Remove(label); err != nil {
+			exitErr(exitFailure, "Failed to remove identity", err)
+		}
+		log.Printf("Removed identity %s", label)
+		if label == viper.GetString("identity") {
+			log.Printf("%s was the identity in use, choose another one with \"wallet use\"", label)
+		}
+	},
+}
+
+func init() {
+	walletCmd.AddCommand(walletRemoveCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// walletRemoveCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// walletRemoveCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,57 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// walletUseCmd represents the wallet use command
+var walletUseCmd = &cobra.Command{
+	Use:   "use <label>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Sets the identity used by the following commands",
+	Long: `Sets the identity used by the following commands.
+			Receives the label of an identity of the wallet, and stores it in the config file.
+			The --identity flag and the HYPERPAY_IDENTITY variable still take precedence.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		label := args[0]
+		if !openWallet().Exists(label) {
+			exitf(exitNotFound, "The identity %s is not in the wallet, import it first", label)
+		}
+		path, err := saveConfigValue("identity", label)
+		if err != nil {
+			exitErr(exitFailure, "Failed to save config", err)
+		}
+		log.Printf("Using identity %s (saved to %s)", label, path)
+	},
+}
+
+func init() {
+	walletCmd.AddCommand(walletUseCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// walletUseCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// walletUseCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
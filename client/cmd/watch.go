@@ -0,0 +1,149 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchAccount string
+	watchTypes   []string
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Args:  cobra.NoArgs,
+	Short: "Streams the events emitted by the contract",
+	Long: `Streams the events emitted by the contract until interrupted.
+			The events can be filtered by account with --account and by type with --type
+			(AccountCreated, AccountClosed, AccountStatusChanged, FundsTransferred,
+			BatchTransferred, AccountFrozen, AccountUnfrozen, EscrowCreated, EscrowClaimed
+			or EscrowRefunded).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		events, err := contract.Subscribe(ctx, client.EventFilter{Names: watchTypes, AccountID: watchAccount})
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to subscribe to contract events", err)
+		}
+
+		log.Println("--> Watching contract events, press Ctrl+C to stop")
+		for event := range events {
+			logEvent(event)
+		}
+	},
+}
+
+// logEvent prints a human readable description of the given event.
+func logEvent(event *client.Event) {
+	switch {
+	case event.Transfer != nil:
+		transfer := event.Transfer
+		log.Printf("[%d %s] %s: %s from %s (balance %s) to %s (balance %s)",
+			event.BlockNumber,
+			event.TxID,
+			event.Name,
+			transfer.Amount.Format(transfer.Currency),
+			transfer.FromID,
+			transfer.FromBalance.Format(transfer.Currency),
+			transfer.ToID,
+			transfer.ToBalance.Format(transfer.ToCurrency),
+		)
+		if transfer.ToCurrency != transfer.Currency {
+			log.Printf("    converted to %s", transfer.ToAmount.Format(transfer.ToCurrency))
+		}
+		if transfer.Fee > 0 {
+			log.Printf("    fee of %s to %s", transfer.Fee.Format(transfer.Currency), transfer.FeeCollector)
+		}
+	case event.Batch != nil:
+		log.Printf("[%d %s] %s: %d transfers", event.BlockNumber, event.TxID, event.Name, len(event.Batch.Transfers))
+		for _, transfer := range event.Batch.Transfers {
+			log.Printf("    %s from %s to %s", transfer.Amount.Format(transfer.Currency), transfer.FromID, transfer.ToID)
+			if transfer.Fee > 0 {
+				log.Printf("      fee of %s to %s", transfer.Fee.Format(transfer.Currency), transfer.FeeCollector)
+			}
+		}
+	case event.Closure != nil:
+		closure := event.Closure
+		log.Printf("[%d %s] %s: %s at %s", event.BlockNumber, event.TxID, event.Name, closure.AccountID, closure.Bank)
+		if closure.SweptTo != "" {
+			log.Printf("    %s swept to %s", closure.SweptAmount.Format(closure.Currency), closure.SweptTo)
+		}
+	case event.Escrow != nil:
+		escrow := event.Escrow
+		log.Printf("[%d %s] %s: %s, %s from %s to %s",
+			event.BlockNumber,
+			event.TxID,
+			event.Name,
+			escrow.EscrowID,
+			escrow.Amount.Format(escrow.Currency),
+			escrow.FromID,
+			escrow.ToID,
+		)
+		if escrow.Preimage != "" {
+			log.Printf("    preimage %s", escrow.Preimage)
+		}
+	case event.Status != nil:
+		log.Printf("[%d %s] %s: %s from %s to %s", event.BlockNumber, event.TxID, event.Name, event.Status.AccountID, event.Status.From, event.Status.To)
+	case event.Freeze != nil:
+		log.Printf("[%d %s] %s: %s, %s freeze (%s)",
+			event.BlockNumber,
+			event.TxID,
+			event.Name,
+			event.Freeze.AccountID,
+			event.Freeze.Mode,
+			event.Freeze.Reason,
+		)
+	case event.Account != nil:
+		log.Printf("[%d %s] %s: %s at %s with balance %s",
+			event.BlockNumber,
+			event.TxID,
+			event.Name,
+			event.Account.AccountID,
+			event.Account.Bank,
+			event.Account.Balance.Format(event.Account.Currency),
+		)
+	default:
+		log.Printf("[%d %s] %s", event.BlockNumber, event.TxID, event.Name)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// watchCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// watchCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	watchCmd.Flags().StringVar(&watchAccount, "account", "", "only show the events involving this account")
+	watchCmd.Flags().StringSliceVar(&watchTypes, "type", nil, "only show events of these types, e.g. "+chaincode.EventFundsTransferred)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/client"
+	"github.com/spf13/cobra"
+)
+
+// whoamiResult is the result printed by the whoami command.
+type whoamiResult struct {
+	ClientID string `json:"ClientID"`
+}
+
+// whoamiCmd represents the whoami command
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Args:  cobra.NoArgs,
+	Short: "Prints the client ID of the identity in use",
+	Long: `Prints the client ID of the identity in use.
+			This is the ID stored as the owner of the accounts the identity creates,
+			and the one to pass to chown to give an account to this identity.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		verifyOutputFormat(outputFormats...)
+		contract, err := client.NewHyperPayContract(contractOptions())
+		if err != nil {
+			exitErr(exitUnavailable, "Failed to create contract client", err)
+		}
+		defer contract.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		log.Println("--> Evaluate Transaction: GetClientID, function returns the ID of the invoking client")
+		clientID, err := contract.ClientID(ctx)
+		if err != nil {
+			exitErr(exitRejected, "Failed to evaluate transaction", err)
+		}
+		printResult(&whoamiResult{ClientID: clientID}, [][]string{{"CLIENT ID"}, {clientID}})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// whoamiCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// whoamiCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
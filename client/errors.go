@@ -0,0 +1,170 @@
+package client
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+)
+
+// Kinds of the errors returned by the transactions. A TransactionError of a kind matches it with
+// errors.Is:
+//
+//	if errors.Is(err, client.ErrInsufficientFunds) {
+//		...
+//	}
+//
+// The error returned by the contract, with its code, can also be got with errors.As:
+//
+//	var contractErr *chaincode.Error
+//	if errors.As(err, &contractErr) && contractErr.Code == chaincode.CodeFailedPrecondition {
+//		...
+//	}
+var (
+	// ErrNotFound is returned when an account or a transfer does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when creating an account that already exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrInsufficientFunds is returned when the source account of a transfer lacks the balance.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrUnauthorized is returned when the identity is not allowed to run the transaction.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrEndorsement is returned when the transaction did not get the endorsements it needs.
+	ErrEndorsement = errors.New("endorsement failure")
+	// ErrInvalidArgument is returned when the contract rejects an argument of the transaction.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrAccountFrozen is returned when a freeze of an account stops the transaction.
+	ErrAccountFrozen = errors.New("account frozen")
+	// ErrLimitExceeded is returned when a transfer exceeds a limit of its source account.
+	ErrLimitExceeded = errors.New("limit exceeded")
+	// ErrFailedPrecondition is returned when the state of the ledger does not allow the
+	// transaction, such as a transfer between accounts in different currencies.
+	ErrFailedPrecondition = errors.New("failed precondition")
+)
+
+// errorKinds maps the codes of the contract errors to their kinds.
+var errorKinds = map[chaincode.ErrorCode]error{
+	chaincode.CodeInvalidArgument:    ErrInvalidArgument,
+	chaincode.CodeNotFound:           ErrNotFound,
+	chaincode.CodeAlreadyExists:      ErrAlreadyExists,
+	chaincode.CodeInsufficientFunds:  ErrInsufficientFunds,
+	chaincode.CodeAccountFrozen:      ErrAccountFrozen,
+	chaincode.CodeLimitExceeded:      ErrLimitExceeded,
+	chaincode.CodeUnauthorized:       ErrUnauthorized,
+	chaincode.CodeFailedPrecondition: ErrFailedPrecondition,
+}
+
+// TransactionError is the error of a transaction the backend failed to run.
+type TransactionError struct {
+	// Function is the contract function of the transaction.
+	Function string
+	// Message is the error message of the contract, or of the network when the transaction
+	// failed outside the contract.
+	Message string
+	// Code is the code of the contract error, or empty when the contract returned no code, as
+	// older versions of it do, or the transaction failed outside the contract.
+	Code chaincode.ErrorCode
+	// Kind is one of the error kinds above, or nil when the error is of none of them.
+	Kind error
+	// Err is the error returned by the backend.
+	Err error
+}
+
+func (e *TransactionError) Error() string {
+	return e.Message
+}
+
+// Is reports whether the error is of the given kind, or a contract error with the same code as
+// the given *chaincode.Error.
+func (e *TransactionError) Is(target error) bool {
+	if t, ok := target.(*chaincode.Error); ok {
+		return e.Code != "" && e.Code == t.Code
+	}
+	return e.Kind != nil && e.Kind == target
+}
+
+// As sets the target to the contract error when it is a **chaincode.Error and the contract
+// returned a code.
+func (e *TransactionError) As(target interface{}) bool {
+	t, ok := target.(**chaincode.Error)
+	if !ok || e.Code == "" {
+		return false
+	}
+	*t = &chaincode.Error{Code: e.Code, Message: e.Message}
+	return true
+}
+
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
+
+// newTransactionError returns the TransactionError of the error returned by the backend for a
+// call to the given contract function. The kind of the error is that of the code returned by the
+// contract, or else it is inferred from the status returned by the network or from the message of
+// the contract.
+func newTransactionError(function string, err error) *TransactionError {
+	txErr := &TransactionError{Function: function, Message: err.Error(), Err: err}
+	if s, ok := status.FromError(err); ok {
+		if chaincodeStatus := findChaincodeStatus(s); chaincodeStatus != nil {
+			txErr.Message = chaincodeStatus.Message
+		} else if isEndorsementFailure(s) {
+			txErr.Kind = ErrEndorsement
+			return txErr
+		}
+	}
+	if contractErr, ok := chaincode.ParseError(txErr.Message); ok {
+		txErr.Code = contractErr.Code
+		txErr.Message = contractErr.Message
+		txErr.Kind = errorKinds[contractErr.Code]
+		return txErr
+	}
+	txErr.Kind = errorKind(txErr.Message)
+	return txErr
+}
+
+// findChaincodeStatus returns the status returned by the contract, which is either the given
+// status or, when the call was sent to several peers, one of its details.
+func findChaincodeStatus(s *status.Status) *status.Status {
+	if s.Group == status.ChaincodeStatus {
+		return s
+	}
+	for _, detail := range s.Details {
+		if err, ok := detail.(error); ok {
+			if detailStatus, ok := status.FromError(err); ok && detailStatus.Group == status.ChaincodeStatus {
+				return detailStatus
+			}
+		}
+	}
+	return nil
+}
+
+// isEndorsementFailure reports whether the status is that of a transaction whose endorsements
+// differ between peers or do not satisfy the endorsement policy.
+func isEndorsementFailure(s *status.Status) bool {
+	switch s.Group {
+	case status.EndorserClientStatus:
+		return s.Code == status.EndorsementMismatch.ToInt32()
+	case status.EventServerStatus:
+		return s.Code == int32(peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE)
+	}
+	return false
+}
+
+// errorKind infers the kind of an error from the message of a contract that returns no codes.
+func errorKind(message string) error {
+	switch {
+	case strings.Contains(message, "does not exist"),
+		strings.Contains(message, "doesn't exist"):
+		return ErrNotFound
+	case strings.Contains(message, "already exist"):
+		return ErrAlreadyExists
+	case strings.Contains(message, "does not have enough balance"):
+		return ErrInsufficientFunds
+	case strings.Contains(message, "is not authorized"),
+		strings.Contains(message, "is not the owner"):
+		return ErrUnauthorized
+	}
+	return nil
+}
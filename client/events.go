@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+)
+
+// Event is a chaincode event emitted by the HyperPay contract.
+type Event struct {
+	Name        string
+	TxID        string
+	BlockNumber uint64
+	// Account is set for the AccountCreated and AccountDeleted events.
+	Account *chaincode.AccountEvent
+	// Transfer is set for the FundsTransferred event.
+	Transfer *chaincode.TransferEvent
+	// Batch is set for the BatchTransferred event.
+	Batch *chaincode.BatchTransferEvent
+	// Freeze is set for the AccountFrozen and AccountUnfrozen events.
+	Freeze *chaincode.FreezeEvent
+	// Closure is set for the AccountClosed event.
+	Closure *chaincode.ClosureEvent
+	// Status is set for the AccountStatusChanged event.
+	Status *chaincode.StatusEvent
+	// Escrow is set for the EscrowCreated, EscrowClaimed and EscrowRefunded events.
+	Escrow *chaincode.EscrowEvent
+}
+
+// EventFilter selects the events delivered by Subscribe. Empty fields match every event.
+type EventFilter struct {
+	// Names are the event names to deliver, e.g. chaincode.EventFundsTransferred.
+	Names []string
+	// AccountID delivers only the events involving the given account.
+	AccountID string
+}
+
+// matches reports whether the event passes the filter.
+func (filter EventFilter) matches(event *Event) bool {
+	if len(filter.Names) > 0 {
+		found := false
+		for _, name := range filter.Names {
+			if name == event.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.AccountID != "" {
+		switch {
+		case event.Account != nil:
+			return event.Account.AccountID == filter.AccountID
+		case event.Transfer != nil:
+			return event.Transfer.FromID == filter.AccountID || event.Transfer.ToID == filter.AccountID ||
+				event.Transfer.FeeCollector == filter.AccountID
+		case event.Freeze != nil:
+			return event.Freeze.AccountID == filter.AccountID
+		case event.Closure != nil:
+			return event.Closure.AccountID == filter.AccountID || event.Closure.SweptTo == filter.AccountID
+		case event.Status != nil:
+			return event.Status.AccountID == filter.AccountID
+		case event.Escrow != nil:
+			return event.Escrow.FromID == filter.AccountID || event.Escrow.ToID == filter.AccountID
+		case event.Batch != nil:
+			for _, transfer := range event.Batch.Transfers {
+				if transfer.FromID == filter.AccountID || transfer.ToID == filter.AccountID || transfer.FeeCollector == filter.AccountID {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Subscribe returns a channel receiving the contract events that match the given filter.
+// The subscription ends, and the channel is closed, when ctx is done or the client is closed.
+func (contract *HyperPayContract) Subscribe(ctx context.Context, filter EventFilter) (<-chan *Event, error) {
+	contract.mu.RLock()
+	if contract.closed {
+		contract.mu.RUnlock()
+		return nil, ErrClosed
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	notifier, err := contract.backend.Events(ctx)
+	if err != nil {
+		contract.mu.RUnlock()
+		cancel()
+		return nil, err
+	}
+	// The subscription is tracked until the backend ends it, and is added before the lock is
+	// released so that Close waits for it.
+	contract.calls.Add(1)
+	contract.mu.RUnlock()
+
+	events := make(chan *Event)
+	go func() {
+		defer contract.calls.Done()
+		defer close(events)
+		// The backend closes notifier once it has ended its subscription.
+		defer func() {
+			cancel()
+			for range notifier {
+			}
+		}()
+		for {
+			select {
+			case ccEvent, ok := <-notifier:
+				if !ok {
+					return
+				}
+				event, err := decodeEvent(ccEvent)
+				if err != nil || !filter.matches(event) {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				case <-contract.done:
+					return
+				}
+			case <-ctx.Done():
+				return
+			case <-contract.done:
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// decodeEvent decodes the payload of a chaincode event into its typed representation.
+func decodeEvent(ccEvent *fab.CCEvent) (*Event, error) {
+	event := &Event{
+		Name:        ccEvent.EventName,
+		TxID:        ccEvent.TxID,
+		BlockNumber: ccEvent.BlockNumber,
+	}
+	switch ccEvent.EventName {
+	case chaincode.EventAccountCreated, chaincode.EventAccountDeleted:
+		event.Account = &chaincode.AccountEvent{}
+		if err := json.Unmarshal(ccEvent.Payload, event.Account); err != nil {
+			return nil, err
+		}
+	case chaincode.EventFundsTransferred:
+		event.Transfer = &chaincode.TransferEvent{}
+		if err := json.Unmarshal(ccEvent.Payload, event.Transfer); err != nil {
+			return nil, err
+		}
+	case chaincode.EventAccountClosed:
+		event.Closure = &chaincode.ClosureEvent{}
+		if err := json.Unmarshal(ccEvent.Payload, event.Closure); err != nil {
+			return nil, err
+		}
+	case chaincode.EventAccountStatusChanged:
+		event.Status = &chaincode.StatusEvent{}
+		if err := json.Unmarshal(ccEvent.Payload, event.Status); err != nil {
+			return nil, err
+		}
+	case chaincode.EventAccountFrozen, chaincode.EventAccountUnfrozen:
+		event.Freeze = &chaincode.FreezeEvent{}
+		if err := json.Unmarshal(ccEvent.Payload, event.Freeze); err != nil {
+			return nil, err
+		}
+	case chaincode.EventEscrowCreated, chaincode.EventEscrowClaimed, chaincode.EventEscrowRefunded:
+		event.Escrow = &chaincode.EscrowEvent{}
+		if err := json.Unmarshal(ccEvent.Payload, event.Escrow); err != nil {
+			return nil, err
+		}
+	case chaincode.EventBatchTransferred:
+		event.Batch = &chaincode.BatchTransferEvent{}
+		if err := json.Unmarshal(ccEvent.Payload, event.Batch); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
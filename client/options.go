@@ -0,0 +1,69 @@
+package client
+
+// Options configures the network, contract and identity used by NewHyperPayContract.
+// Empty fields take the value of DefaultOptions.
+type Options struct {
+	// ChannelID is the channel the contract is deployed on.
+	ChannelID string
+	// ChaincodeName is the name the contract was deployed with.
+	ChaincodeName string
+	// Identity is the label of the wallet identity that signs the transactions.
+	Identity string
+	// WalletPath is the directory of the file system wallet.
+	WalletPath string
+	// MSPDir is the MSP directory the identity is imported from when it is not in the wallet.
+	MSPDir string
+	// MSPID is the MSP ID of the identity imported from MSPDir.
+	MSPID string
+	// CCPPath is the connection profile describing the network.
+	CCPPath string
+	// Backend selects where the transactions run: BackendGateway or BackendSim.
+	Backend string
+	// SimLedgerPath is the file the simulator keeps its ledger in. When empty the ledger is only
+	// kept in memory, for the lifetime of the client.
+	SimLedgerPath string
+}
+
+// DefaultOptions returns the options for the org1 user of the test network.
+func DefaultOptions() Options {
+	return Options{
+		ChannelID:     "mychannel",
+		ChaincodeName: "mycc",
+		Identity:      "User1@org1.example.com",
+		WalletPath:    "wallet",
+		MSPDir:        "msp",
+		MSPID:         "Org1MSP",
+		CCPPath:       "ccp.yaml",
+		Backend:       BackendGateway,
+	}
+}
+
+// withDefaults returns a copy of the options whose empty fields are set to their default value.
+func (opts Options) withDefaults() Options {
+	defaults := DefaultOptions()
+	if opts.ChannelID == "" {
+		opts.ChannelID = defaults.ChannelID
+	}
+	if opts.ChaincodeName == "" {
+		opts.ChaincodeName = defaults.ChaincodeName
+	}
+	if opts.Identity == "" {
+		opts.Identity = defaults.Identity
+	}
+	if opts.WalletPath == "" {
+		opts.WalletPath = defaults.WalletPath
+	}
+	if opts.MSPDir == "" {
+		opts.MSPDir = defaults.MSPDir
+	}
+	if opts.MSPID == "" {
+		opts.MSPID = defaults.MSPID
+	}
+	if opts.CCPPath == "" {
+		opts.CCPPath = defaults.CCPPath
+	}
+	if opts.Backend == "" {
+		opts.Backend = defaults.Backend
+	}
+	return opts
+}
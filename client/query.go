@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+)
+
+// ListPage returns a page of at most pageSize accounts, in ID order, starting at the given bookmark.
+// An empty bookmark gets the first page, and the returned page has an empty bookmark when it is the last one.
+func (contract *HyperPayContract) ListPage(ctx context.Context, pageSize int32, bookmark string) (*chaincode.AccountPage, error) {
+	result, err := contract.evaluate(ctx, "ListAccounts", fmt.Sprint(pageSize), bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAccountPage(result)
+}
+
+// QueryPage returns a page of at most pageSize accounts passing the given filter, starting at the given bookmark.
+func (contract *HyperPayContract) QueryPage(ctx context.Context, filter chaincode.AccountFilter, pageSize int32, bookmark string) (*chaincode.AccountPage, error) {
+	result, err := contract.evaluate(ctx, "QueryAccounts",
+		filter.Bank,
+		filter.Owner,
+		filter.Currency,
+		fmt.Sprint(int64(filter.MinBalance)),
+		fmt.Sprint(int64(filter.MaxBalance)),
+		fmt.Sprint(pageSize),
+		bookmark,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAccountPage(result)
+}
+
+// List returns an iterator over every account, fetching pageSize accounts at a time.
+func (contract *HyperPayContract) List(ctx context.Context, pageSize int32) *AccountIterator {
+	return &AccountIterator{fetch: func(bookmark string) (*chaincode.AccountPage, error) {
+		return contract.ListPage(ctx, pageSize, bookmark)
+	}}
+}
+
+// Query returns an iterator over the accounts passing the given filter, fetching pageSize accounts at a time.
+func (contract *HyperPayContract) Query(ctx context.Context, filter chaincode.AccountFilter, pageSize int32) *AccountIterator {
+	return &AccountIterator{fetch: func(bookmark string) (*chaincode.AccountPage, error) {
+		return contract.QueryPage(ctx, filter, pageSize, bookmark)
+	}}
+}
+
+// AccountIterator iterates over the accounts returned by List and Query, fetching the pages as
+// needed:
+//
+//	it := contract.List(ctx, 50)
+//	for it.Next() {
+//		account := it.Account()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type AccountIterator struct {
+	fetch    func(bookmark string) (*chaincode.AccountPage, error)
+	accounts []*chaincode.Account
+	bookmark string
+	started  bool
+	current  *chaincode.Account
+	err      error
+}
+
+// Next advances to the next account, fetching the next page when the current one is exhausted.
+// It returns false at the end of the accounts or on error.
+func (it *AccountIterator) Next() bool {
+	for len(it.accounts) == 0 {
+		if it.err != nil || (it.started && it.bookmark == "") {
+			it.current = nil
+			return false
+		}
+		page, err := it.fetch(it.bookmark)
+		if err != nil {
+			it.err = err
+			it.current = nil
+			return false
+		}
+		it.started = true
+		it.accounts = page.Accounts
+		it.bookmark = page.Bookmark
+	}
+	it.current = it.accounts[0]
+	it.accounts = it.accounts[1:]
+	return true
+}
+
+// Account returns the current account.
+func (it *AccountIterator) Account() *chaincode.Account {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *AccountIterator) Err() error {
+	return it.err
+}
+
+func decodeAccountPage(result []byte) (*chaincode.AccountPage, error) {
+	var page chaincode.AccountPage
+	err := json.Unmarshal(result, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
@@ -1,62 +1,128 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
-	"io/ioutil"
-	"os"
-	"path/filepath"
-
-	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
-	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+	"sync"
+	"time"
 )
 
+// ErrClosed is returned by the calls made on a HyperPayContract after it was closed.
+var ErrClosed = errors.New("the contract client is closed")
+
+// HyperPayContract is a client of the HyperPay smart contract. It owns a backend, usually a gateway
+// connection, that is kept open until Close is called, and it is safe for concurrent use by
+// multiple goroutines.
+//
+// Every call receives a context: when the context is done the call returns its error without
+// waiting for the network, although a transaction already sent to the orderer may still commit.
 type HyperPayContract struct {
-	c *gateway.Contract
+	backend Backend
+
+	mu     sync.RWMutex
+	closed bool
+	// calls tracks the backend calls in flight, including those whose caller gave up, and the
+	// running subscriptions, so that Close does not close the backend under them.
+	calls sync.WaitGroup
+	// done is closed by Close to end the running subscriptions.
+	done chan struct{}
 }
 
-func NewHyperPayContract() (*HyperPayContract, error) {
-	const (
-		channelId = "mychannel"
-		identity  = "User1@org1.example.com"
-	)
-	err := os.Setenv("DISCOVERY_AS_LOCALHOST", "true")
+// NewHyperPayContract connects to the HyperPay contract described by the given options.
+// The returned client must be closed.
+func NewHyperPayContract(opts Options) (*HyperPayContract, error) {
+	backend, err := newBackend(opts.withDefaults())
 	if err != nil {
 		return nil, err
 	}
-	wallet, err := gateway.NewFileSystemWallet("wallet")
-	if err != nil {
-		return nil, err
+	return NewHyperPayContractWithBackend(backend), nil
+}
+
+// NewHyperPayContractWithBackend returns a client running its transactions on the given backend,
+// which is closed along with the client.
+func NewHyperPayContractWithBackend(backend Backend) *HyperPayContract {
+	return &HyperPayContract{backend: backend, done: make(chan struct{})}
+}
+
+// Close ends the running subscriptions and closes the backend once they and the calls in flight
+// have returned. Calls made after Close fail with ErrClosed.
+func (contract *HyperPayContract) Close() error {
+	contract.mu.Lock()
+	if contract.closed {
+		contract.mu.Unlock()
+		return nil
 	}
-	if !wallet.Exists(identity) {
-		if err := populateWallet(wallet); err != nil {
-			return nil, err
-		}
+	contract.closed = true
+	close(contract.done)
+	contract.mu.Unlock()
+
+	contract.calls.Wait()
+	return contract.backend.Close()
+}
+
+// submit submits a transaction to the ledger, giving up when ctx is done.
+func (contract *HyperPayContract) submit(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return contract.call(ctx, contract.backend.Submit, name, args...)
+}
+
+// evaluate evaluates a transaction without submitting it, giving up when ctx is done.
+func (contract *HyperPayContract) evaluate(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return contract.call(ctx, contract.backend.Evaluate, name, args...)
+}
+
+// call runs a transaction with the given backend function. The errors of the transaction are
+// returned as TransactionErrors, while ErrClosed and the error of ctx are returned as they are.
+func (contract *HyperPayContract) call(ctx context.Context, fn func(context.Context, string, ...string) ([]byte, error), name string, args ...string) ([]byte, error) {
+	contract.mu.RLock()
+	if contract.closed {
+		contract.mu.RUnlock()
+		return nil, ErrClosed
 	}
-	ccpPath := filepath.Join(
-		"ccp.yaml",
-	)
-	gw, err := gateway.Connect(
-		gateway.WithConfig(config.FromFile(filepath.Clean(ccpPath))),
-		gateway.WithIdentity(wallet, identity),
-	)
-	if err != nil {
+	if err := ctx.Err(); err != nil {
+		contract.mu.RUnlock()
 		return nil, err
 	}
-	defer gw.Close()
+	// The call is tracked until fn returns, even when ctx is done first, and is added before the
+	// lock is released so that Close waits for it.
+	contract.calls.Add(1)
+	contract.mu.RUnlock()
 
-	network, err := gw.GetNetwork(channelId)
-	if err != nil {
-		return nil, err
+	type result struct {
+		payload []byte
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer contract.calls.Done()
+		payload, err := fn(ctx, name, args...)
+		done <- result{payload, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, newTransactionError(name, r.err)
+		}
+		return r.payload, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-	return &HyperPayContract{c: network.GetContract("mycc")}, nil
 }
 
-// Init populates the blockchain with some accounts.
-func (contract *HyperPayContract) Init() error {
-	_, err := contract.c.SubmitTransaction("InitLedger")
+// Init populates the blockchain with the given seed accounts, or with a default set when seed is empty.
+// When force is set, seed accounts that already exist are reset instead of making Init fail.
+func (contract *HyperPayContract) Init(ctx context.Context, seed []chaincode.SeedAccount, force bool) error {
+	if seed == nil {
+		seed = []chaincode.SeedAccount{}
+	}
+	seedJSON, err := json.Marshal(seed)
+	if err != nil {
+		return err
+	}
+	_, err = contract.submit(ctx, "InitLedger", string(seedJSON), fmt.Sprint(force))
 	if err != nil {
 		return err
 	}
@@ -64,8 +130,8 @@ func (contract *HyperPayContract) Init() error {
 }
 
 // Read reads the details of the given account.
-func (contract *HyperPayContract) Read(id string) (*chaincode.Account, error) {
-	result, err := contract.c.EvaluateTransaction("ReadAccount", id)
+func (contract *HyperPayContract) Read(ctx context.Context, id string) (*chaincode.Account, error) {
+	result, err := contract.evaluate(ctx, "ReadAccount", id)
 	if err != nil {
 		return nil, err
 	}
@@ -77,18 +143,77 @@ func (contract *HyperPayContract) Read(id string) (*chaincode.Account, error) {
 	return &account, nil
 }
 
-// Transfer transfers the given amount from the given source account to the given destination account.
-func (contract *HyperPayContract) Transfer(fromId, toId string, amount float32) error {
-	_, err := contract.c.SubmitTransaction("Transfer", fromId, toId, fmt.Sprint(amount))
+// Transfer transfers the given amount from the given source account to the given destination account,
+// with an optional memo, and returns the record of the transfer.
+func (contract *HyperPayContract) Transfer(ctx context.Context, fromId, toId string, amount chaincode.Amount, memo string) (*chaincode.TransferRecord, error) {
+	result, err := contract.submit(ctx, "Transfer", fromId, toId, fmt.Sprint(int64(amount)), memo)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	var transfer chaincode.TransferRecord
+	err = json.Unmarshal(result, &transfer)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// QuoteTransfer returns the fee that Transfer would charge for the given amount from the given source
+// account to the given destination account, without making the transfer.
+func (contract *HyperPayContract) QuoteTransfer(ctx context.Context, fromId, toId string, amount chaincode.Amount) (*chaincode.FeeQuote, error) {
+	result, err := contract.evaluate(ctx, "QuoteTransfer", fromId, toId, fmt.Sprint(int64(amount)))
+	if err != nil {
+		return nil, err
+	}
+	var quote chaincode.FeeQuote
+	err = json.Unmarshal(result, &quote)
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// BatchTransfer makes the given transfers atomically, either all of them or none, and returns their
+// records in the order of the batch.
+func (contract *HyperPayContract) BatchTransfer(ctx context.Context, transfers []chaincode.BatchTransferItem) ([]*chaincode.TransferRecord, error) {
+	transfersJSON, err := json.Marshal(transfers)
+	if err != nil {
+		return nil, err
+	}
+	result, err := contract.submit(ctx, "BatchTransfer", string(transfersJSON))
+	if err != nil {
+		return nil, err
+	}
+	var records []*chaincode.TransferRecord
+	err = json.Unmarshal(result, &records)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// CheckBatchTransfer returns the transfers of the given batch that would make BatchTransfer fail,
+// without making any of them.
+func (contract *HyperPayContract) CheckBatchTransfer(ctx context.Context, transfers []chaincode.BatchTransferItem) ([]*chaincode.BatchTransferFailure, error) {
+	transfersJSON, err := json.Marshal(transfers)
+	if err != nil {
+		return nil, err
+	}
+	result, err := contract.evaluate(ctx, "CheckBatchTransfer", string(transfersJSON))
+	if err != nil {
+		return nil, err
+	}
+	var failures []*chaincode.BatchTransferFailure
+	err = json.Unmarshal(result, &failures)
+	if err != nil {
+		return nil, err
+	}
+	return failures, nil
 }
 
 // Exists determines whether an account with the given ID exists.
-func (contract *HyperPayContract) Exists(id string) (bool, error) {
-	result, err := contract.c.EvaluateTransaction("AccountExists", id)
+func (contract *HyperPayContract) Exists(ctx context.Context, id string) (bool, error) {
+	result, err := contract.evaluate(ctx, "AccountExists", id)
 	if err != nil {
 		return false, err
 	}
@@ -100,27 +225,112 @@ func (contract *HyperPayContract) Exists(id string) (bool, error) {
 	return exists, nil
 }
 
-// Create creates an account with the given id, balance and bank information.
-func (contract *HyperPayContract) Create(id string, balance float32, bank string) error {
-	_, err := contract.c.SubmitTransaction("CreateAccount", id, fmt.Sprint(balance), bank)
+// Create creates an account with the given id, balance, bank, currency and type information. The type
+// is chaincode.AccountChecking, AccountSavings or AccountBusiness.
+func (contract *HyperPayContract) Create(ctx context.Context, id string, balance chaincode.Amount, bank, currency, accountType string) error {
+	_, err := contract.submit(ctx, "CreateAccount", id, fmt.Sprint(int64(balance)), bank, currency, accountType)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete closes the given account, which must have a zero balance.
+//
+// Deprecated: use CloseAccount, which can sweep the balance of the account.
+func (contract *HyperPayContract) Delete(ctx context.Context, id string) error {
+	_, err := contract.submit(ctx, "DeleteAccount", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// TransferOwnership makes the client with the given ID the owner of the given account.
+func (contract *HyperPayContract) TransferOwnership(ctx context.Context, id, newOwner string) error {
+	_, err := contract.submit(ctx, "TransferOwnership", id, newOwner)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CloseAccount closes the given account, sweeping its balance to the sweepTo account. The account
+// must have a zero balance when sweepTo is empty. Its ID cannot be reused once closed.
+func (contract *HyperPayContract) CloseAccount(ctx context.Context, id, sweepTo string) error {
+	_, err := contract.submit(ctx, "CloseAccount", id, sweepTo)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetStatus moves the given account to the given status, chaincode.StatusPending, StatusActive or
+// StatusDormant.
+func (contract *HyperPayContract) SetStatus(ctx context.Context, id, status string) error {
+	_, err := contract.submit(ctx, "SetAccountStatus", id, status)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// Delete deletes the given account.
-func (contract *HyperPayContract) Delete(id string) error {
-	_, err := contract.c.SubmitTransaction("DeleteAccount", id)
+// Freeze freezes the given account in the given mode, chaincode.FreezeDebit, FreezeCredit or
+// FreezeFull, recording the reason code.
+func (contract *HyperPayContract) Freeze(ctx context.Context, id, mode, reason string) error {
+	_, err := contract.submit(ctx, "FreezeAccount", id, mode, reason)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// Unfreeze lifts the freeze of the given account.
+func (contract *HyperPayContract) Unfreeze(ctx context.Context, id string) error {
+	_, err := contract.submit(ctx, "UnfreezeAccount", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Limits returns the limits of the given account, with what it has debited in the current day and
+// month.
+func (contract *HyperPayContract) Limits(ctx context.Context, id string) (*chaincode.AccountLimits, error) {
+	result, err := contract.evaluate(ctx, "GetAccountLimits", id)
+	if err != nil {
+		return nil, err
+	}
+	var limits chaincode.AccountLimits
+	err = json.Unmarshal(result, &limits)
+	if err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
+// SetLimits sets the maximum amount of a single transfer from the given account, and the maximum
+// total it can send in a day and in a month. A zero limit removes that limit.
+func (contract *HyperPayContract) SetLimits(ctx context.Context, id string, maxTransfer, daily, monthly chaincode.Amount) error {
+	_, err := contract.submit(ctx, "SetAccountLimits", id, fmt.Sprint(int64(maxTransfer)), fmt.Sprint(int64(daily)), fmt.Sprint(int64(monthly)))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClientID returns the ID of the identity used by the client, as stored in the owner of its accounts.
+func (contract *HyperPayContract) ClientID(ctx context.Context) (string, error) {
+	result, err := contract.evaluate(ctx, "GetClientID")
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
 // Txs returns all transactions involving given account.
-func (contract *HyperPayContract) Txs(id string) ([]chaincode.TxRecord, error) {
-	result, err := contract.c.EvaluateTransaction("GetAllTxs", id)
+func (contract *HyperPayContract) Txs(ctx context.Context, id string) ([]chaincode.TxRecord, error) {
+	result, err := contract.evaluate(ctx, "GetAllTxs", id)
 	if err != nil {
 		return nil, err
 	}
@@ -132,38 +342,148 @@ func (contract *HyperPayContract) Txs(id string) ([]chaincode.TxRecord, error) {
 	return txs, nil
 }
 
-func populateWallet(wallet *gateway.Wallet) error {
-	credPath := filepath.Join(
-		"msp",
-	)
+// TransferWithConversion transfers the given amount, in the currency of the source account, to an
+// account holding another currency, converting it with the exchange rate stored on the ledger.
+// The transfer is recorded with an optional memo.
+func (contract *HyperPayContract) TransferWithConversion(ctx context.Context, fromId, toId string, amount chaincode.Amount, memo string) (*chaincode.Conversion, error) {
+	result, err := contract.submit(ctx, "TransferWithConversion", fromId, toId, fmt.Sprint(int64(amount)), memo)
+	if err != nil {
+		return nil, err
+	}
+	var conversion chaincode.Conversion
+	err = json.Unmarshal(result, &conversion)
+	if err != nil {
+		return nil, err
+	}
+	return &conversion, nil
+}
 
-	certPath := filepath.Join(credPath, "signcerts", "cert.pem")
-	// read the certificate pem
-	cert, err := ioutil.ReadFile(filepath.Clean(certPath))
+// SetRate stores the rate at which one unit of base converts into quote.
+func (contract *HyperPayContract) SetRate(ctx context.Context, base, quote, rate string) error {
+	_, err := contract.submit(ctx, "SetFXRate", base, quote, rate)
 	if err != nil {
 		return err
 	}
+	return nil
+}
 
-	keyDir := filepath.Join(credPath, "keystore")
-	// there's a single file in this dir containing the private key
-	files, err := ioutil.ReadDir(keyDir)
+// Rates returns every exchange rate stored on the ledger.
+func (contract *HyperPayContract) Rates(ctx context.Context) ([]*chaincode.FXRate, error) {
+	result, err := contract.evaluate(ctx, "GetFXRates")
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var rates []*chaincode.FXRate
+	err = json.Unmarshal(result, &rates)
+	if err != nil {
+		return nil, err
 	}
-	if len(files) != 1 {
-		return errors.New("keystore folder should have contain one file")
+	return rates, nil
+}
+
+// SetFXConfig sets the orgs allowed to set exchange rates and the maximum age of a usable rate.
+func (contract *HyperPayContract) SetFXConfig(ctx context.Context, rateSetters []string, maxRateAge time.Duration) error {
+	rateSettersJSON, err := json.Marshal(rateSetters)
+	if err != nil {
+		return err
 	}
-	keyPath := filepath.Join(keyDir, files[0].Name())
-	key, err := ioutil.ReadFile(filepath.Clean(keyPath))
+	_, err = contract.submit(ctx, "SetFXConfig", string(rateSettersJSON), fmt.Sprint(int64(maxRateAge/time.Second)))
 	if err != nil {
 		return err
 	}
+	return nil
+}
+
+// CreateEscrow locks the given amount of the given payer account in an escrow for the given payee
+// account, until the preimage of the hashlock, a hex encoded SHA-256 hash, claims it or it expires at
+// the given time.
+func (contract *HyperPayContract) CreateEscrow(ctx context.Context, fromId, toId string, amount chaincode.Amount, hashlock string, expiry time.Time) (*chaincode.Escrow, error) {
+	result, err := contract.submit(ctx, "CreateEscrow", fromId, toId, fmt.Sprint(int64(amount)), hashlock, expiry.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalEscrow(result)
+}
+
+// ClaimEscrow releases the funds of the given escrow to its payee with the hex encoded preimage of its
+// hashlock, and returns the claimed escrow.
+func (contract *HyperPayContract) ClaimEscrow(ctx context.Context, id, preimage string) (*chaincode.Escrow, error) {
+	result, err := contract.submit(ctx, "ClaimEscrow", id, preimage)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalEscrow(result)
+}
+
+// RefundEscrow returns the funds of the given expired escrow to its payer, and returns the refunded
+// escrow.
+func (contract *HyperPayContract) RefundEscrow(ctx context.Context, id string) (*chaincode.Escrow, error) {
+	result, err := contract.submit(ctx, "RefundEscrow", id)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalEscrow(result)
+}
 
-	identity := gateway.NewX509Identity("Org1MSP", string(cert), string(key))
+// Escrow returns the escrow with the given ID.
+func (contract *HyperPayContract) Escrow(ctx context.Context, id string) (*chaincode.Escrow, error) {
+	result, err := contract.evaluate(ctx, "GetEscrow", id)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalEscrow(result)
+}
 
-	err = wallet.Put("appUser", identity)
+// unmarshalEscrow decodes an escrow returned by the contract.
+func unmarshalEscrow(result []byte) (*chaincode.Escrow, error) {
+	var escrow chaincode.Escrow
+	err := json.Unmarshal(result, &escrow)
+	if err != nil {
+		return nil, err
+	}
+	return &escrow, nil
+}
+
+// Fees returns the fee schedule stored on the ledger.
+func (contract *HyperPayContract) Fees(ctx context.Context) (*chaincode.FeeSchedule, error) {
+	result, err := contract.evaluate(ctx, "GetFeeSchedule")
+	if err != nil {
+		return nil, err
+	}
+	var schedule chaincode.FeeSchedule
+	err = json.Unmarshal(result, &schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// SetFees replaces the rules of the fee schedule. No rules stops charging fees.
+func (contract *HyperPayContract) SetFees(ctx context.Context, rules []chaincode.FeeRule) error {
+	if rules == nil {
+		rules = []chaincode.FeeRule{}
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	_, err = contract.submit(ctx, "SetFeeSchedule", string(rulesJSON))
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// Migrate rewrites the accounts stored with an older format and returns how many were migrated.
+func (contract *HyperPayContract) Migrate(ctx context.Context) (int, error) {
+	result, err := contract.submit(ctx, "MigrateAccounts")
+	if err != nil {
+		return 0, err
+	}
+	var migrated int
+	err = json.Unmarshal(result, &migrated)
+	if err != nil {
+		return 0, err
+	}
+	return migrated, nil
+}
@@ -0,0 +1,343 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode/memledger"
+)
+
+// simClients are clients of an admin and a customer sharing the in-memory ledger of a sim backend,
+// seeded with the default accounts owned by the admin.
+type simClients struct {
+	admin    *HyperPayContract
+	customer *HyperPayContract
+	ledger   *memledger.Ledger
+}
+
+// testChaincode and testIdentities are shared by the tests, since building them is slow.
+var (
+	testChaincode  *contractapi.ContractChaincode
+	testIdentities map[string]*memledger.Identity
+)
+
+// newSimClients returns clients on a new seeded ledger.
+func newSimClients(t *testing.T) *simClients {
+	t.Helper()
+
+	if testChaincode == nil {
+		cc, err := contractapi.NewChaincode(new(chaincode.SmartContract))
+		if err != nil {
+			t.Fatalf("failed to create the chaincode: %v", err)
+		}
+		admin, err := memledger.NewIdentity("Org1MSP", "Admin@org1.example.com", []string{"admin"}, nil)
+		if err != nil {
+			t.Fatalf("failed to create the admin identity: %v", err)
+		}
+		customer, err := memledger.NewIdentity("Org1MSP", "User1@org1.example.com", []string{"client"}, nil)
+		if err != nil {
+			t.Fatalf("failed to create the customer identity: %v", err)
+		}
+		testChaincode = cc
+		testIdentities = map[string]*memledger.Identity{"admin": admin, "customer": customer}
+	}
+
+	ledger := memledger.NewLedger("mychannel", "Org1MSP")
+	clients := &simClients{
+		admin:    NewHyperPayContractWithBackend(&simBackend{cc: testChaincode, identity: testIdentities["admin"], ledger: ledger}),
+		customer: NewHyperPayContractWithBackend(&simBackend{cc: testChaincode, identity: testIdentities["customer"], ledger: ledger}),
+		ledger:   ledger,
+	}
+	if err := clients.admin.Init(context.Background(), nil, false); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	return clients
+}
+
+// expectBalance checks the balance of the given account.
+func (clients *simClients) expectBalance(t *testing.T, id string, balance chaincode.Amount) {
+	t.Helper()
+	account, err := clients.admin.Read(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if account.Balance != balance {
+		t.Errorf("the balance of %s is %d, want %d", id, account.Balance, balance)
+	}
+}
+
+func TestSimContract(t *testing.T) {
+	cases := []struct {
+		name string
+		// call makes the calls of the case, checking their results, and returns the error of the
+		// last one.
+		call func(t *testing.T, ctx context.Context, clients *simClients) error
+		// kind is the kind of the error returned by call, nil when it succeeds.
+		kind error
+		// code is the contract code of the error returned by call.
+		code chaincode.ErrorCode
+	}{
+		{
+			name: "Read",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				account, err := clients.customer.Read(ctx, "account1")
+				if err == nil && (account.Balance != 10000 || account.Currency != "USD") {
+					t.Errorf("got %+v", account)
+				}
+				return err
+			},
+		},
+		{
+			name: "Create and Exists",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				if err := clients.admin.Create(ctx, "account6", 2500, "BankA", "JPY", chaincode.AccountSavings); err != nil {
+					return err
+				}
+				exists, err := clients.customer.Exists(ctx, "account6")
+				if err == nil && !exists {
+					t.Errorf("the account does not exist")
+				}
+				return err
+			},
+		},
+		{
+			name: "Transfer",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				transfer, err := clients.admin.Transfer(ctx, "account1", "account2", 2550, "rent")
+				if err != nil {
+					return err
+				}
+				if transfer.Amount != 2550 || transfer.Memo != "rent" {
+					t.Errorf("got %+v", transfer)
+				}
+				clients.expectBalance(t, "account1", 7450)
+				clients.expectBalance(t, "account2", 22550)
+				stored, err := clients.customer.GetTransfer(ctx, transfer.ID)
+				if err == nil && stored.ToID != "account2" {
+					t.Errorf("got %+v", stored)
+				}
+				return err
+			},
+		},
+		{
+			name: "BatchTransfer",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				records, err := clients.admin.BatchTransfer(ctx, []chaincode.BatchTransferItem{
+					{FromID: "account1", ToID: "account2", Amount: 100},
+					{FromID: "account4", ToID: "account1", Amount: 300},
+				})
+				if err != nil {
+					return err
+				}
+				if len(records) != 2 {
+					t.Errorf("got %d records, want 2", len(records))
+				}
+				clients.expectBalance(t, "account1", 10200)
+				return nil
+			},
+		},
+		{
+			name: "TransferWithConversion",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				if err := clients.admin.SetRate(ctx, "USD", "EUR", "0.5"); err != nil {
+					return err
+				}
+				conversion, err := clients.admin.TransferWithConversion(ctx, "account1", "account3", 1000, "")
+				if err == nil && conversion.ToAmount != 500 {
+					t.Errorf("got %+v", conversion)
+				}
+				return err
+			},
+		},
+		{
+			name: "Statement",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				if _, err := clients.admin.Transfer(ctx, "account1", "account2", 1000, ""); err != nil {
+					return err
+				}
+				statement, err := clients.customer.Statement(ctx, "account1", time.Time{}, time.Time{})
+				if err == nil && (statement.ClosingBalance != 9000 || len(statement.Entries) != 2) {
+					t.Errorf("got %+v", statement)
+				}
+				return err
+			},
+		},
+		{
+			name: "ClientID",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				id, err := clients.admin.ClientID(ctx)
+				if err != nil {
+					return err
+				}
+				account, err := clients.admin.Read(ctx, "account1")
+				if err == nil && account.Owner != id {
+					t.Errorf("the owner is %s, want %s", account.Owner, id)
+				}
+				return err
+			},
+		},
+		{
+			name: "Read of a missing account",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				_, err := clients.admin.Read(ctx, "nope")
+				return err
+			},
+			kind: ErrNotFound,
+			code: chaincode.CodeNotFound,
+		},
+		{
+			name: "Create of an existing account",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				return clients.admin.Create(ctx, "account1", 0, "BankA", "USD", chaincode.AccountChecking)
+			},
+			kind: ErrAlreadyExists,
+			code: chaincode.CodeAlreadyExists,
+		},
+		{
+			name: "Transfer with insufficient balance",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				_, err := clients.admin.Transfer(ctx, "account1", "account2", 10001, "")
+				return err
+			},
+			kind: ErrInsufficientFunds,
+			code: chaincode.CodeInsufficientFunds,
+		},
+		{
+			name: "Transfer from an account of another owner",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				_, err := clients.customer.Transfer(ctx, "account1", "account2", 100, "")
+				return err
+			},
+			kind: ErrUnauthorized,
+			code: chaincode.CodeUnauthorized,
+		},
+		{
+			name: "Init by a customer",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				return clients.customer.Init(ctx, nil, true)
+			},
+			kind: ErrUnauthorized,
+			code: chaincode.CodeUnauthorized,
+		},
+		{
+			name: "Transfer between currencies",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				_, err := clients.admin.Transfer(ctx, "account1", "account3", 100, "")
+				return err
+			},
+			kind: ErrFailedPrecondition,
+			code: chaincode.CodeFailedPrecondition,
+		},
+		{
+			name: "Transfer from a frozen account",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				if err := clients.admin.Freeze(ctx, "account1", chaincode.FreezeDebit, "AML_REVIEW"); err != nil {
+					return err
+				}
+				_, err := clients.admin.Transfer(ctx, "account1", "account2", 100, "")
+				return err
+			},
+			kind: ErrAccountFrozen,
+			code: chaincode.CodeAccountFrozen,
+		},
+		{
+			name: "Transfer above the limit",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				if err := clients.admin.SetLimits(ctx, "account1", 500, 0, 0); err != nil {
+					return err
+				}
+				_, err := clients.admin.Transfer(ctx, "account1", "account2", 501, "")
+				return err
+			},
+			kind: ErrLimitExceeded,
+			code: chaincode.CodeLimitExceeded,
+		},
+		{
+			name: "Create with an invalid currency",
+			call: func(t *testing.T, ctx context.Context, clients *simClients) error {
+				return clients.admin.Create(ctx, "account6", 0, "BankA", "XXX", chaincode.AccountChecking)
+			},
+			kind: ErrInvalidArgument,
+			code: chaincode.CodeInvalidArgument,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			clients := newSimClients(t)
+			defer clients.admin.Close()
+			defer clients.customer.Close()
+
+			err := c.call(t, context.Background(), clients)
+			if c.kind == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, c.kind) {
+				t.Fatalf("got error %v, want %v", err, c.kind)
+			}
+			var contractErr *chaincode.Error
+			if !errors.As(err, &contractErr) || contractErr.Code != c.code {
+				t.Errorf("got contract error %+v, want code %s", contractErr, c.code)
+			}
+			if !errors.Is(err, &chaincode.Error{Code: c.code}) {
+				t.Errorf("the error does not match the code %s", c.code)
+			}
+		})
+	}
+}
+
+func TestSimContractClosed(t *testing.T) {
+	clients := newSimClients(t)
+	if err := clients.admin.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := clients.admin.Close(); err != nil {
+		t.Fatalf("the second Close failed: %v", err)
+	}
+	if _, err := clients.admin.Read(context.Background(), "account1"); err != ErrClosed {
+		t.Errorf("got error %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestSimContractClosedSubscription(t *testing.T) {
+	clients := newSimClients(t)
+	events, err := clients.admin.Subscribe(context.Background(), EventFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- clients.admin.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not end the subscription")
+	}
+	for range events {
+	}
+	if _, err := clients.admin.Subscribe(context.Background(), EventFilter{}); err != ErrClosed {
+		t.Errorf("got error %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestSimContractCanceled(t *testing.T) {
+	clients := newSimClients(t)
+	defer clients.admin.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := clients.admin.Read(ctx, "account1"); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode/memledger"
+)
+
+// simEventPollInterval is how often the simulator looks for new events.
+const simEventPollInterval = 500 * time.Millisecond
+
+// simBackend runs the HyperPay contract in process against an in-memory ledger. The peer endorsing
+// the transactions belongs to the MSP of the client identity.
+//
+// When a ledger file is given, the ledger is read from it before every call and written back after
+// every submitted transaction, so that successive commands share it. Processes using the same file
+// at the same time may overwrite each other's transactions.
+type simBackend struct {
+	cc       *contractapi.ContractChaincode
+	identity *memledger.Identity
+	path     string
+
+	mu     sync.Mutex
+	ledger *memledger.Ledger
+}
+
+func newSimBackend(opts Options) (*simBackend, error) {
+	wallet, err := openWallet(opts)
+	if err != nil {
+		return nil, err
+	}
+	walletIdentity, err := getX509Identity(wallet, opts.Identity)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := contractapi.NewChaincode(new(chaincode.SmartContract))
+	if err != nil {
+		return nil, err
+	}
+
+	backend := &simBackend{
+		cc: cc,
+		identity: &memledger.Identity{
+			MSPID:       walletIdentity.MspID,
+			Certificate: []byte(walletIdentity.Certificate()),
+		},
+		path:   opts.SimLedgerPath,
+		ledger: memledger.NewLedger(opts.ChannelID, walletIdentity.MspID),
+	}
+	if err := backend.load(); err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+func (backend *simBackend) Submit(ctx context.Context, name string, args ...string) ([]byte, error) {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if err := backend.load(); err != nil {
+		return nil, err
+	}
+
+	_, response := backend.ledger.Submit(backend.cc, backend.identity, append([]string{name}, args...)...)
+	if response.Status >= shim.ERRORTHRESHOLD {
+		return nil, errors.New(response.Message)
+	}
+	if err := backend.save(); err != nil {
+		return nil, err
+	}
+	return response.Payload, nil
+}
+
+func (backend *simBackend) Evaluate(ctx context.Context, name string, args ...string) ([]byte, error) {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if err := backend.load(); err != nil {
+		return nil, err
+	}
+
+	response := backend.ledger.Evaluate(backend.cc, backend.identity, append([]string{name}, args...)...)
+	if response.Status >= shim.ERRORTHRESHOLD {
+		return nil, errors.New(response.Message)
+	}
+	return response.Payload, nil
+}
+
+// Events polls the ledger, and its file, for the events committed after the call.
+func (backend *simBackend) Events(ctx context.Context) (<-chan *fab.CCEvent, error) {
+	committed, err := backend.committedEvents()
+	if err != nil {
+		return nil, err
+	}
+	delivered := len(committed)
+
+	events := make(chan *fab.CCEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(simEventPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			committed, err := backend.committedEvents()
+			if err != nil {
+				continue
+			}
+			for ; delivered < len(committed); delivered++ {
+				event := committed[delivered]
+				ccEvent := &fab.CCEvent{
+					TxID:        event.TxID,
+					EventName:   event.Name,
+					Payload:     event.Payload,
+					BlockNumber: event.BlockNumber,
+				}
+				select {
+				case events <- ccEvent:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (backend *simBackend) Close() error {
+	return nil
+}
+
+func (backend *simBackend) committedEvents() ([]memledger.Event, error) {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if err := backend.load(); err != nil {
+		return nil, err
+	}
+	return backend.ledger.Events(), nil
+}
+
+// load reads the ledger from its file, if any and it exists.
+func (backend *simBackend) load() error {
+	if backend.path == "" {
+		return nil
+	}
+	file, err := os.Open(backend.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ledger, err := memledger.Load(file, backend.identity.MSPID)
+	if err != nil {
+		return err
+	}
+	backend.ledger = ledger
+	return nil
+}
+
+// save writes the ledger to its file, if any, replacing the file atomically.
+func (backend *simBackend) save() error {
+	if backend.path == "" {
+		return nil
+	}
+	file, err := ioutil.TempFile(filepath.Dir(backend.path), filepath.Base(backend.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := backend.ledger.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), backend.path)
+}
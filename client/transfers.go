@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lllrdgz/cc-hyperpay-go/hyperpay-transfer/chaincode"
+)
+
+// GetTransfer returns the record of the transfer with the given ID.
+func (contract *HyperPayContract) GetTransfer(ctx context.Context, transferID string) (*chaincode.TransferRecord, error) {
+	result, err := contract.evaluate(ctx, "GetTransfer", transferID)
+	if err != nil {
+		return nil, err
+	}
+	var transfer chaincode.TransferRecord
+	err = json.Unmarshal(result, &transfer)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// Statement returns the statement of the given account for the transactions committed from the
+// from time, inclusive, to the to time, exclusive. A zero time leaves the period unbounded on that side.
+func (contract *HyperPayContract) Statement(ctx context.Context, accountID string, from, to time.Time) (*chaincode.Statement, error) {
+	result, err := contract.evaluate(ctx, "GetStatement", accountID, formatStatementTime(from), formatStatementTime(to))
+	if err != nil {
+		return nil, err
+	}
+	var statement chaincode.Statement
+	err = json.Unmarshal(result, &statement)
+	if err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// TransfersPage returns a page of at most pageSize transfers from or to the given account, in the
+// order they happened, starting at the given bookmark. An empty bookmark gets the first page, and
+// the returned page has an empty bookmark when it is the last one.
+func (contract *HyperPayContract) TransfersPage(ctx context.Context, accountID string, pageSize int32, bookmark string) (*chaincode.TransferPage, error) {
+	result, err := contract.evaluate(ctx, "ListTransfersForAccount", accountID, fmt.Sprint(pageSize), bookmark)
+	if err != nil {
+		return nil, err
+	}
+	var page chaincode.TransferPage
+	err = json.Unmarshal(result, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Transfers returns an iterator over the transfers from or to the given account, fetching pageSize
+// transfers at a time.
+func (contract *HyperPayContract) Transfers(ctx context.Context, accountID string, pageSize int32) *TransferIterator {
+	return &TransferIterator{fetch: func(bookmark string) (*chaincode.TransferPage, error) {
+		return contract.TransfersPage(ctx, accountID, pageSize, bookmark)
+	}}
+}
+
+// TransferIterator iterates over the transfers returned by Transfers, fetching the pages as needed,
+// the same way AccountIterator does.
+type TransferIterator struct {
+	fetch     func(bookmark string) (*chaincode.TransferPage, error)
+	transfers []*chaincode.TransferRecord
+	bookmark  string
+	started   bool
+	current   *chaincode.TransferRecord
+	err       error
+}
+
+// Next advances to the next transfer, fetching the next page when the current one is exhausted.
+// It returns false at the end of the transfers or on error.
+func (it *TransferIterator) Next() bool {
+	for len(it.transfers) == 0 {
+		if it.err != nil || (it.started && it.bookmark == "") {
+			it.current = nil
+			return false
+		}
+		page, err := it.fetch(it.bookmark)
+		if err != nil {
+			it.err = err
+			it.current = nil
+			return false
+		}
+		it.started = true
+		it.transfers = page.Transfers
+		it.bookmark = page.Bookmark
+	}
+	it.current = it.transfers[0]
+	it.transfers = it.transfers[1:]
+	return true
+}
+
+// Transfer returns the current transfer.
+func (it *TransferIterator) Transfer() *chaincode.TransferRecord {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *TransferIterator) Err() error {
+	return it.err
+}
+
+func formatStatementTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// IdentityInfo describes an identity stored in a wallet.
+type IdentityInfo struct {
+	Label string
+	MSPID string
+	// CommonName and OrganizationalUnits are read from the subject of the certificate.
+	CommonName          string
+	OrganizationalUnits []string
+	Expires             time.Time
+}
+
+// ImportIdentity reads the certificate and private key of the MSP directory mspDir, as generated
+// by cryptogen or the Fabric CA, and stores them in the wallet under the given label. The keystore
+// may hold several keys: the one matching the certificate is imported.
+func ImportIdentity(wallet *gateway.Wallet, label, mspDir, mspID string) error {
+	if label == "" {
+		return errors.New("the identity label must not be empty")
+	}
+	if mspID == "" {
+		return errors.New("the MSP ID must not be empty")
+	}
+
+	certPEM, err := readSignCert(filepath.Join(mspDir, "signcerts"))
+	if err != nil {
+		return err
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := findPrivateKey(filepath.Join(mspDir, "keystore"), cert)
+	if err != nil {
+		return err
+	}
+
+	return wallet.Put(label, gateway.NewX509Identity(mspID, string(certPEM), string(keyPEM)))
+}
+
+// ExportIdentity writes the identity stored in the wallet under the given label to the MSP
+// directory mspDir, with the layout read by ImportIdentity.
+func ExportIdentity(wallet *gateway.Wallet, label, mspDir string) error {
+	identity, err := getX509Identity(wallet, label)
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		filepath.Join(mspDir, "signcerts", "cert.pem"): identity.Certificate(),
+		filepath.Join(mspDir, "keystore", "priv_sk"):   identity.Key(),
+	}
+	for path := range files {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListIdentities describes the identities stored in the wallet, sorted by label.
+func ListIdentities(wallet *gateway.Wallet) ([]IdentityInfo, error) {
+	labels, err := wallet.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(labels)
+
+	infos := make([]IdentityInfo, 0, len(labels))
+	for _, label := range labels {
+		identity, err := getX509Identity(wallet, label)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := parseCertificate([]byte(identity.Certificate()))
+		if err != nil {
+			return nil, fmt.Errorf("identity %s: %v", label, err)
+		}
+		infos = append(infos, IdentityInfo{
+			Label:               label,
+			MSPID:               identity.MspID,
+			CommonName:          cert.Subject.CommonName,
+			OrganizationalUnits: cert.Subject.OrganizationalUnit,
+			Expires:             cert.NotAfter,
+		})
+	}
+	return infos, nil
+}
+
+// getX509Identity gets the X.509 identity stored in the wallet under the given label.
+func getX509Identity(wallet *gateway.Wallet, label string) (*gateway.X509Identity, error) {
+	if !wallet.Exists(label) {
+		return nil, fmt.Errorf("the identity %s is not in the wallet", label)
+	}
+	identity, err := wallet.Get(label)
+	if err != nil {
+		return nil, err
+	}
+	x509Identity, ok := identity.(*gateway.X509Identity)
+	if !ok {
+		return nil, fmt.Errorf("the identity %s is not an X.509 identity", label)
+	}
+	return x509Identity, nil
+}
+
+// readSignCert reads the certificate of the signcerts directory. It is cert.pem when present, and
+// otherwise the only file of the directory.
+func readSignCert(dir string) ([]byte, error) {
+	cert, err := ioutil.ReadFile(filepath.Join(dir, "cert.pem"))
+	if err == nil || !os.IsNotExist(err) {
+		return cert, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) != 1 {
+		return nil, fmt.Errorf("the signcerts folder %s should contain a single certificate, found %d files", dir, len(files))
+	}
+	return ioutil.ReadFile(filepath.Join(dir, files[0].Name()))
+}
+
+// findPrivateKey reads the files of the keystore directory and returns the PEM encoded private
+// key whose public key is the one of the certificate.
+func findPrivateKey(dir string, cert *x509.Certificate) ([]byte, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	certPublicKey, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		keyPEM, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		// Files that are not private keys are skipped.
+		key, err := parsePrivateKey(keyPEM)
+		if err != nil {
+			continue
+		}
+		publicKey, err := x509.MarshalPKIXPublicKey(key.Public())
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(publicKey, certPublicKey) {
+			return keyPEM, nil
+		}
+	}
+	return nil, fmt.Errorf("no private key in the keystore folder %s matches the certificate", dir)
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("the certificate is not PEM encoded")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("the private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key type")
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}